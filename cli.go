@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"forum/database"
+	"forum/handlers"
+	"forum/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const usage = `Usage: forum <commande> [arguments]
+
+Commandes:
+  serve                                          démarre le serveur web (par défaut)
+  migrate up | rollback [n] | status             gère les migrations du schéma
+  user create [-role r] [-password p] <nom> <email>
+                                                 crée un compte (mot de passe lu sur l'entrée standard si absent)
+  user promote <nom> <guest|user|moderator|admin>
+                                                 change le rôle d'un utilisateur
+  user ban [-reason texte] <nom>                 suspend un compte et révoque ses sessions
+  user unban <nom>                               lève la suspension d'un compte
+  tag merge <source> <cible>                     fusionne le tag source dans le tag cible
+  backup [fichier]                               copie la base dans un fichier
+  reindex                                        reconstruit les index de la base
+  recount                                        recalcule les compteurs de likes et dislikes`
+
+// runCommand exécute la sous-commande demandée et retourne le code de sortie
+func runCommand(args []string) int {
+	if len(args) == 0 {
+		runServe()
+		return 0
+	}
+
+	switch args[0] {
+	case "serve":
+		runServe()
+		return 0
+	case "migrate":
+		return runMigrateCommand(args[1:])
+	case "user":
+		return runUserCommand(args[1:])
+	case "tag":
+		return runTagCommand(args[1:])
+	case "backup":
+		return runBackupCommand(args[1:])
+	case "reindex":
+		return runReindexCommand(args[1:])
+	case "recount":
+		return runRecountCommand(args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+}
+
+// openDatabase ouvre la base et applique les migrations en attente
+func openDatabase() (*sql.DB, error) {
+	db, err := database.InitDB()
+	if err != nil {
+		return nil, err
+	}
+	if err := database.RunMigrations(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// usageError affiche l'aide et retourne le code de sortie associé
+func usageError(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n\n", args...)
+	fmt.Fprintln(os.Stderr, usage)
+	return 2
+}
+
+func runUserCommand(args []string) int {
+	if len(args) == 0 {
+		return usageError("Sous-commande user manquante")
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		log.Printf("Échec d'initialisation de la DB: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	userStore := models.NewUserStore(db)
+	sessionStore := models.NewSessionStore(db)
+
+	switch args[0] {
+	case "create":
+		return userCreate(userStore, args[1:])
+
+	case "promote":
+		if len(args) != 3 {
+			return usageError("Usage: forum user promote <nom> <rôle>")
+		}
+		role, ok := parseRole(args[2])
+		if !ok {
+			return usageError("Rôle inconnu: %s", args[2])
+		}
+		user, err := userStore.GetByUsername(args[1])
+		if err != nil {
+			log.Printf("Utilisateur %s introuvable: %v", args[1], err)
+			return 1
+		}
+		if err := userStore.UpdateRole(user.ID, role); err != nil {
+			log.Printf("Échec du changement de rôle: %v", err)
+			return 1
+		}
+		fmt.Printf("%s est maintenant %s (ancien rôle: %s)\n", user.Username, role, user.Role)
+
+	case "ban":
+		fs := flag.NewFlagSet("user ban", flag.ContinueOnError)
+		reason := fs.String("reason", "", "motif de la suspension")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if fs.NArg() != 1 {
+			return usageError("Usage: forum user ban [-reason texte] <nom>")
+		}
+		user, err := userStore.GetByUsername(fs.Arg(0))
+		if err != nil {
+			log.Printf("Utilisateur %s introuvable: %v", fs.Arg(0), err)
+			return 1
+		}
+		if err := userStore.Ban(user.ID, *reason); err != nil {
+			log.Printf("Échec de la suspension: %v", err)
+			return 1
+		}
+		if err := sessionStore.DeleteByUserID(user.ID); err != nil {
+			log.Printf("Échec de la révocation des sessions: %v", err)
+			return 1
+		}
+		fmt.Printf("%s est suspendu\n", user.Username)
+
+	case "unban":
+		if len(args) != 2 {
+			return usageError("Usage: forum user unban <nom>")
+		}
+		user, err := userStore.GetByUsername(args[1])
+		if err != nil {
+			log.Printf("Utilisateur %s introuvable: %v", args[1], err)
+			return 1
+		}
+		if err := userStore.Unban(user.ID); err != nil {
+			log.Printf("Échec de la levée de suspension: %v", err)
+			return 1
+		}
+		fmt.Printf("%s n'est plus suspendu\n", user.Username)
+
+	default:
+		return usageError("Sous-commande user inconnue: %s", args[0])
+	}
+
+	return 0
+}
+
+// userCreate crée un compte comme le ferait le formulaire d'inscription
+func userCreate(userStore *models.UserStore, args []string) int {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	roleName := fs.String("role", "user", "rôle du compte")
+	password := fs.String("password", "", "mot de passe (lu sur l'entrée standard si absent)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		return usageError("Usage: forum user create [-role r] [-password p] <nom> <email>")
+	}
+	username, email := fs.Arg(0), fs.Arg(1)
+
+	role, ok := parseRole(*roleName)
+	if !ok {
+		return usageError("Rôle inconnu: %s", *roleName)
+	}
+
+	if _, err := userStore.GetByUsername(username); err == nil {
+		log.Printf("Le nom d'utilisateur %s est déjà pris", username)
+		return 1
+	}
+	if _, err := userStore.GetByEmail(email); err == nil {
+		log.Printf("L'email %s est déjà enregistré", email)
+		return 1
+	}
+
+	if *password == "" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Printf("Impossible de lire le mot de passe: %v", err)
+			return 1
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+	if *password == "" {
+		return usageError("Le mot de passe est obligatoire")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Erreur hachage mot de passe: %v", err)
+		return 1
+	}
+	uuid, err := handlers.GenerateUUID()
+	if err != nil {
+		log.Printf("Erreur génération UUID: %v", err)
+		return 1
+	}
+
+	user := &models.User{
+		UUID:      uuid,
+		Username:  username,
+		Email:     email,
+		Password:  string(hashedPassword),
+		AvatarURL: "/static/assets/pfp_placeholder.jpg",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userStore.Create(user); err != nil {
+		log.Printf("Impossible de créer le compte: %v", err)
+		return 1
+	}
+	if role != models.RoleUser {
+		if err := userStore.UpdateRole(user.ID, role); err != nil {
+			log.Printf("Échec de l'attribution du rôle: %v", err)
+			return 1
+		}
+	}
+
+	fmt.Printf("Utilisateur %s créé (ID %d, rôle %s)\n", user.Username, user.ID, role)
+	return 0
+}
+
+// parseRole n'accepte que les noms de rôle connus
+func parseRole(name string) (models.UserRole, bool) {
+	role := models.ParseUserRole(name)
+	return role, role.String() == strings.ToLower(strings.TrimSpace(name))
+}
+
+func runTagCommand(args []string) int {
+	if len(args) != 3 || args[0] != "merge" {
+		return usageError("Usage: forum tag merge <source> <cible>")
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		log.Printf("Échec d'initialisation de la DB: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	tagStore := models.NewTagStore(db)
+	source, err := tagStore.GetByName(args[1])
+	if err != nil {
+		log.Printf("Tag %s introuvable: %v", args[1], err)
+		return 1
+	}
+	target, err := tagStore.GetByName(args[2])
+	if err != nil {
+		log.Printf("Tag %s introuvable: %v", args[2], err)
+		return 1
+	}
+	if source.ID == target.ID {
+		return usageError("Les tags source et cible sont identiques")
+	}
+
+	moved, err := tagStore.Merge(source.ID, target.ID)
+	if err != nil {
+		log.Printf("Échec de la fusion des tags: %v", err)
+		return 1
+	}
+
+	fmt.Printf("Tag %s fusionné dans %s (%d post(s) rattaché(s))\n", source.Name, target.Name, moved)
+	return 0
+}
+
+func runBackupCommand(args []string) int {
+	if len(args) > 1 {
+		return usageError("Usage: forum backup [fichier]")
+	}
+	path := fmt.Sprintf("forum-%s.db", time.Now().Format("20060102-150405"))
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	db, err := database.InitDB()
+	if err != nil {
+		log.Printf("Échec d'initialisation de la DB: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := database.Backup(db, path); err != nil {
+		log.Printf("Échec de la sauvegarde: %v", err)
+		return 1
+	}
+
+	fmt.Printf("Sauvegarde écrite dans %s\n", path)
+	return 0
+}
+
+func runReindexCommand(args []string) int {
+	if len(args) != 0 {
+		return usageError("Usage: forum reindex")
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		log.Printf("Échec d'initialisation de la DB: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := database.Reindex(db); err != nil {
+		log.Printf("Échec de la réindexation: %v", err)
+		return 1
+	}
+
+	fmt.Println("Index reconstruits")
+	return 0
+}
+
+// runRecountCommand corrige les compteurs de réactions qui ne correspondent plus à la table reactions
+func runRecountCommand(args []string) int {
+	if len(args) != 0 {
+		return usageError("Usage: forum recount")
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		log.Printf("Échec d'initialisation de la DB: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	posts, comments, err := database.RecountReactions(db)
+	if err != nil {
+		log.Printf("Échec du recalcul des compteurs: %v", err)
+		return 1
+	}
+
+	fmt.Printf("Compteurs recalculés : %d post(s) et %d commentaire(s) corrigé(s)\n", posts, comments)
+	return 0
+}
@@ -3,7 +3,6 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"time"
@@ -53,25 +52,3 @@ func InitDB() (*sql.DB, error) {
 func GetDB() *sql.DB {
 	return DB
 }
-
-// RunMigrations runs the database migration
-func RunMigrations(db *sql.DB) error {
-	migrationPath := "./database/000_initial_schema.sql"
-	log.Printf("Applying initial database schema")
-
-	migration, err := os.ReadFile(migrationPath)
-	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
-	}
-
-	// Désactivez le mode transaction automatique
-	db.SetMaxOpenConns(1)
-
-	// Exécutez directement les commandes SQL sans transaction explicite
-	if _, err := db.Exec(string(migration)); err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
-	}
-
-	log.Printf("Database schema applied successfully")
-	return nil
-}
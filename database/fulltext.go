@@ -0,0 +1,123 @@
+package database
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"log"
+)
+
+// Index FTS5 et triggers de synchronisation de la recherche plein texte
+//
+//go:embed fulltext.sql
+var fullTextSchema string
+
+// HasFTS5 indique si SQLite a été compilé avec la recherche plein texte FTS5,
+// absente du pilote sans l'option de build sqlite_fts5
+func HasFTS5(db *sql.DB) (bool, error) {
+	var enabled bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return false, fmt.Errorf("failed to inspect sqlite options: %w", err)
+	}
+	return enabled, nil
+}
+
+// Triggers de synchronisation créés par fulltext.sql
+var fullTextTriggers = []string{
+	"posts_fts_insert", "posts_fts_delete", "posts_fts_update",
+	"comments_fts_insert", "comments_fts_delete", "comments_fts_update",
+}
+
+// SetupFullTextSearch crée les index plein texte s'ils manquent et que SQLite
+// dispose de FTS5. Ils ne font pas partie des migrations versionnées : sans
+// FTS5, la recherche se replie sur LIKE et le schéma reste complet.
+func SetupFullTextSearch(db *sql.DB) error {
+	fts5, err := HasFTS5(db)
+	if err != nil {
+		return err
+	}
+
+	exists, err := tableExists(db, "posts_fts")
+	if err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	synced, err := triggerExists(db, fullTextTriggers[0])
+	if err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
+	switch {
+	case exists && !fts5:
+		// Les triggers de synchronisation échoueraient à chaque écriture : ils
+		// sont retirés, et les index reconstruits par un binaire avec FTS5
+		log.Printf("Full-text indexes disabled: sqlite was built without FTS5, search falls back to LIKE")
+		return dropFullTextTriggers(db)
+	case exists && synced:
+		return nil
+	case !fts5:
+		log.Printf("Full-text indexes skipped: sqlite was built without FTS5, search falls back to LIKE")
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if exists {
+		// Index laissés sans synchronisation par un binaire sans FTS5
+		log.Printf("Rebuilding stale full-text indexes")
+		for _, index := range fullTextIndexes {
+			if _, err := tx.Exec("DROP TABLE IF EXISTS " + index); err != nil {
+				return fmt.Errorf("failed to drop %s: %w", index, err)
+			}
+		}
+	}
+
+	log.Printf("Creating full-text indexes")
+	if _, err := tx.Exec(fullTextSchema); err != nil {
+		return fmt.Errorf("failed to create full-text indexes: %w", err)
+	}
+	return tx.Commit()
+}
+
+// removeOrphanFullTextSearch supprime les index plein texte quand les tables
+// qu'ils indexent n'existent plus, après l'annulation de toutes les migrations
+func removeOrphanFullTextSearch(db *sql.DB) error {
+	indexed, err := tableExists(db, "posts")
+	if err != nil || indexed {
+		return err
+	}
+	exists, err := tableExists(db, "posts_fts")
+	if err != nil || !exists {
+		return err
+	}
+	fts5, err := HasFTS5(db)
+	if err != nil {
+		return err
+	}
+	if !fts5 {
+		// Sans le module FTS5, SQLite refuse de supprimer ses tables virtuelles
+		log.Printf("Full-text indexes left in place: sqlite was built without FTS5")
+		return nil
+	}
+
+	log.Printf("Dropping full-text indexes")
+	for _, index := range fullTextIndexes {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + index); err != nil {
+			return fmt.Errorf("failed to drop %s: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// dropFullTextTriggers retire les triggers de synchronisation des index plein texte
+func dropFullTextTriggers(db *sql.DB) error {
+	for _, trigger := range fullTextTriggers {
+		if _, err := db.Exec("DROP TRIGGER IF EXISTS " + trigger); err != nil {
+			return fmt.Errorf("failed to drop trigger %s: %w", trigger, err)
+		}
+	}
+	return nil
+}
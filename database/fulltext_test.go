@@ -0,0 +1,96 @@
+package database
+
+import "testing"
+
+func TestSetupFullTextSearch(t *testing.T) {
+	db := openTestDB(t)
+	fts5, err := HasFTS5(db)
+	if err != nil {
+		t.Fatalf("HasFTS5() failed: %v", err)
+	}
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() failed: %v", err)
+	}
+	exists, err := tableExists(db, "posts_fts")
+	if err != nil {
+		t.Fatalf("failed to inspect schema: %v", err)
+	}
+	if exists != fts5 {
+		t.Fatalf("posts_fts exists = %v, want %v", exists, fts5)
+	}
+
+	// Une seconde exécution ne recrée rien
+	if err := SetupFullTextSearch(db); err != nil {
+		t.Fatalf("second SetupFullTextSearch() failed: %v", err)
+	}
+	if !fts5 {
+		return
+	}
+
+	// Les index suivent les posts créés après leur mise en place
+	if _, err := db.Exec(`INSERT INTO users (uuid, username, email, password) VALUES ('u1', 'alice', 'alice@exemple.fr', 'hash')`); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO posts (user_id, title, content) VALUES (1, 'Modèle de données', 'Question')`); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM posts_fts WHERE posts_fts MATCH 'modele'").Scan(&count); err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("full-text search found %d posts, want 1", count)
+	}
+	// Annuler la migration de la recherche plein texte conserve les index
+	states, err := MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() failed: %v", err)
+	}
+	steps := 0
+	for _, state := range states {
+		if state.Applied && state.Version >= 10 {
+			steps++
+		}
+	}
+	if err := RollbackMigrations(db, steps); err != nil {
+		t.Fatalf("RollbackMigrations(%d) failed: %v", steps, err)
+	}
+	if exists, err := tableExists(db, "posts_fts"); err != nil || !exists {
+		t.Errorf("posts_fts exists = %v, %v after rolling back migration 010, want true", exists, err)
+	}
+}
+
+func TestSetupFullTextSearchWithoutFTS5(t *testing.T) {
+	db := openTestDB(t)
+	if fts5, err := HasFTS5(db); err != nil || fts5 {
+		t.Skip("sqlite is built with FTS5")
+	}
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() failed: %v", err)
+	}
+
+	// Index laissés par un binaire compilé avec FTS5, simulés par une table
+	// ordinaire et un trigger qui échoue sans le module
+	schema := []string{
+		`CREATE TABLE posts_fts (title, content)`,
+		`CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+			SELECT RAISE(ABORT, 'no such module: fts5');
+		END`,
+	}
+	for _, statement := range schema {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("failed to simulate full-text indexes: %v", err)
+		}
+	}
+
+	if err := SetupFullTextSearch(db); err != nil {
+		t.Fatalf("SetupFullTextSearch() failed: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (uuid, username, email, password) VALUES ('u1', 'alice', 'alice@exemple.fr', 'hash')`); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO posts (user_id, title, content) VALUES (1, 'Modèle de données', 'Question')`); err != nil {
+		t.Errorf("writes still go through the full-text triggers: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// Backup écrit une copie cohérente de la base dans le fichier indiqué,
+// sans interrompre les connexions en cours
+func Backup(db *sql.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file %s already exists", path)
+	}
+	if _, err := db.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// Index plein texte reconstruits par Reindex
+var fullTextIndexes = []string{"posts_fts", "comments_fts"}
+
+// Reindex reconstruit les index, y compris ceux de la recherche plein texte,
+// et met à jour les statistiques du planificateur
+func Reindex(db *sql.DB) error {
+	if _, err := db.Exec("REINDEX"); err != nil {
+		return fmt.Errorf("failed to rebuild indexes: %w", err)
+	}
+	fts5, err := HasFTS5(db)
+	if err != nil {
+		return err
+	}
+	for _, index := range fullTextIndexes {
+		exists, err := tableExists(db, index)
+		if err != nil {
+			return fmt.Errorf("failed to inspect schema: %w", err)
+		}
+		// Sans FTS5, les index existants ne sont pas utilisables
+		if !exists || !fts5 {
+			continue
+		}
+		// Commandes spéciales FTS5 : le nom de la table sert de colonne de contrôle
+		if _, err := db.Exec(fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('rebuild')", index)); err != nil {
+			return fmt.Errorf("failed to rebuild %s: %w", index, err)
+		}
+		if _, err := db.Exec(fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('optimize')", index)); err != nil {
+			return fmt.Errorf("failed to optimize %s: %w", index, err)
+		}
+	}
+	if _, err := db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+	return nil
+}
+
+// RecountReactions recalcule les compteurs de likes et dislikes des posts et des
+// commentaires à partir de la table reactions. Retourne le nombre de posts et de
+// commentaires dont les compteurs étaient faux.
+func RecountReactions(db *sql.DB) (posts, comments int64, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if posts, err = recountTable(tx, "posts", "post_id"); err != nil {
+		return 0, 0, err
+	}
+	if comments, err = recountTable(tx, "comments", "comment_id"); err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit recount: %w", err)
+	}
+	return posts, comments, nil
+}
+
+// recountTable corrige les compteurs d'une table réagie ; column est la colonne
+// de reactions qui la référence. IS NOT compare aussi les compteurs NULL.
+func recountTable(tx *sql.Tx, table, column string) (int64, error) {
+	likes := fmt.Sprintf("(SELECT COUNT(*) FROM reactions WHERE reactions.%s = %s.id AND type = 'like')", column, table)
+	dislikes := fmt.Sprintf("(SELECT COUNT(*) FROM reactions WHERE reactions.%s = %s.id AND type = 'dislike')", column, table)
+
+	result, err := tx.Exec(fmt.Sprintf(
+		"UPDATE %s SET like_count = %s, dislike_count = %s WHERE like_count IS NOT %s OR dislike_count IS NOT %s",
+		table, likes, dislikes, likes, dislikes,
+	))
+	if err != nil {
+		return 0, fmt.Errorf("failed to recount %s reactions: %w", table, err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,312 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Les fichiers SQL sont embarqués dans le binaire : le serveur ne dépend plus du répertoire courant
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Format des fichiers : 001_nom.up.sql / 001_nom.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration représente une évolution versionnée du schéma
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState décrit l'état d'une migration dans la base
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// legacyMarkers reconnaît les migrations déjà présentes dans une base créée
+// par l'ancien fichier 000_initial_schema.sql, avant l'existence de schema_migrations
+var legacyMarkers = map[int]func(db *sql.DB) (bool, error){
+	1: func(db *sql.DB) (bool, error) { return tableExists(db, "users") },
+	2: func(db *sql.DB) (bool, error) { return columnExists(db, "activities", "recipient_id") },
+	3: func(db *sql.DB) (bool, error) { return tableExists(db, "sessions") },
+	4: func(db *sql.DB) (bool, error) { return columnExists(db, "reports", "comment_id") },
+}
+
+// LoadMigrations lit les migrations embarquées, triées par version
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("conflicting names for migration %d: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// RunMigrations applique toutes les migrations en attente, puis crée les
+// index plein texte si SQLite le permet
+func RunMigrations(db *sql.DB) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := prepareMigrationTable(db, migrations)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, migration := range migrations {
+		if _, done := applied[migration.Version]; done {
+			continue
+		}
+		log.Printf("Applying migration %03d_%s", migration.Version, migration.Name)
+		if err := applyMigration(db, migration); err != nil {
+			return err
+		}
+		count++
+	}
+
+	log.Printf("Database schema up to date (%d migration(s) applied)", count)
+	return SetupFullTextSearch(db)
+}
+
+// RollbackMigrations annule les dernières migrations appliquées
+func RollbackMigrations(db *sql.DB, steps int) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := prepareMigrationTable(db, migrations)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	for i := 0; i < steps && i < len(versions); i++ {
+		migration, exists := known[versions[i]]
+		if !exists {
+			return fmt.Errorf("migration %d is applied but unknown to this binary", versions[i])
+		}
+		log.Printf("Rolling back migration %03d_%s", migration.Version, migration.Name)
+		if err := revertMigration(db, migration); err != nil {
+			return err
+		}
+	}
+
+	return removeOrphanFullTextSearch(db)
+}
+
+// MigrationStatus retourne l'état de chaque migration connue
+func MigrationStatus(db *sql.DB) ([]MigrationState, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := prepareMigrationTable(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, migration := range migrations {
+		appliedAt, done := applied[migration.Version]
+		states = append(states, MigrationState{
+			Migration: migration,
+			Applied:   done,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return states, nil
+}
+
+// prepareMigrationTable crée la table schema_migrations si besoin et retourne les versions appliquées
+func prepareMigrationTable(db *sql.DB, migrations []Migration) (map[int]time.Time, error) {
+	exists, err := tableExists(db, "schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
+	if !exists {
+		_, err := db.Exec(`
+			CREATE TABLE schema_migrations (
+				version INTEGER PRIMARY KEY,
+				name TEXT NOT NULL,
+				applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)
+		`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+
+		if err := adoptLegacySchema(db, migrations); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// adoptLegacySchema enregistre comme appliquées les migrations déjà présentes
+// dans une base existante, afin de ne pas les rejouer
+func adoptLegacySchema(db *sql.DB, migrations []Migration) error {
+	for _, migration := range migrations {
+		marker, exists := legacyMarkers[migration.Version]
+		if !exists {
+			continue
+		}
+		present, err := marker(db)
+		if err != nil {
+			return fmt.Errorf("failed to inspect schema: %w", err)
+		}
+		if !present {
+			continue
+		}
+		log.Printf("Migration %03d_%s already present, marked as applied", migration.Version, migration.Name)
+		if _, err := db.Exec(
+			"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			migration.Version, migration.Name, time.Now(),
+		); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration exécute une migration et l'enregistre dans la même transaction
+func applyMigration(db *sql.DB, migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.Up); err != nil {
+		return fmt.Errorf("failed to apply migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		migration.Version, migration.Name, time.Now(),
+	); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// revertMigration annule une migration et supprime son enregistrement dans la même transaction
+func revertMigration(db *sql.DB, migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.Down); err != nil {
+		return fmt.Errorf("failed to roll back migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version); err != nil {
+		return fmt.Errorf("failed to unrecord migration %d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// tableExists vérifie la présence d'une table
+func tableExists(db *sql.DB, table string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	return count > 0, err
+}
+
+// triggerExists vérifie la présence d'un trigger
+func triggerExists(db *sql.DB, trigger string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = ?", trigger).Scan(&count)
+	return count > 0, err
+}
+
+// columnExists vérifie la présence d'une colonne dans une table
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	return count > 0, err
+}
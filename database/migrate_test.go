@@ -0,0 +1,193 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// openTestDB ouvre une base vide, configurée comme celle du serveur
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "forum.db")+"?_foreign_keys=on&_journal_mode=WAL&_timeout=5000")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(10)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// userTables liste les tables créées par les migrations
+func userTables(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table'
+		AND name NOT LIKE 'sqlite_%' AND name != 'schema_migrations'
+		ORDER BY name`)
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("failed to scan table name: %v", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables
+}
+
+func TestMigrationsUpAndDown(t *testing.T) {
+	db := openTestDB(t)
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("LoadMigrations() failed: %v", err)
+	}
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() failed: %v", err)
+	}
+	if max := db.Stats().MaxOpenConnections; max != 10 {
+		t.Errorf("RunMigrations() changed the pool size to %d", max)
+	}
+
+	states, err := MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() failed: %v", err)
+	}
+	// Toute la chaîne s'applique, que SQLite dispose de FTS5 ou non
+	applied := 0
+	for _, state := range states {
+		if !state.Applied {
+			t.Errorf("migration %03d_%s not applied", state.Version, state.Name)
+			continue
+		}
+		applied++
+	}
+	if len(userTables(t, db)) == 0 {
+		t.Fatal("no table created by the migrations")
+	}
+
+	// Une seconde exécution ne rejoue rien
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("second RunMigrations() failed: %v", err)
+	}
+
+	if err := RollbackMigrations(db, len(migrations)); err != nil {
+		t.Fatalf("RollbackMigrations() failed: %v", err)
+	}
+	if tables := userTables(t, db); len(tables) != 0 {
+		t.Errorf("tables left after rolling back every migration: %v", tables)
+	}
+	states, err = MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() failed: %v", err)
+	}
+	for _, state := range states {
+		if state.Applied {
+			t.Errorf("migration %03d_%s still applied after rollback", state.Version, state.Name)
+		}
+	}
+
+	// Les migrations annulées peuvent être rejouées
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() after rollback failed: %v", err)
+	}
+	states, err = MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() failed: %v", err)
+	}
+	reapplied := 0
+	for _, state := range states {
+		if state.Applied {
+			reapplied++
+		}
+	}
+	if reapplied != applied {
+		t.Errorf("%d migrations applied after rollback, want %d", reapplied, applied)
+	}
+}
+
+func TestRollbackSteps(t *testing.T) {
+	db := openTestDB(t)
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() failed: %v", err)
+	}
+
+	before, err := MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() failed: %v", err)
+	}
+	last := 0
+	for _, state := range before {
+		if state.Applied {
+			last = state.Version
+		}
+	}
+
+	if err := RollbackMigrations(db, 1); err != nil {
+		t.Fatalf("RollbackMigrations(1) failed: %v", err)
+	}
+
+	after, err := MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() failed: %v", err)
+	}
+	for i, state := range after {
+		want := before[i].Applied && state.Version != last
+		if state.Applied != want {
+			t.Errorf("migration %03d_%s applied = %v, want %v", state.Version, state.Name, state.Applied, want)
+		}
+	}
+}
+
+func TestExplicitStatuses(t *testing.T) {
+	db := openTestDB(t)
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() failed: %v", err)
+	}
+	if err := RollbackMigrations(db, 1); err != nil {
+		t.Fatalf("RollbackMigrations(1) failed: %v", err)
+	}
+
+	// Contenus antérieurs aux migrations, puis post en attente de validation
+	inserts := []string{
+		`INSERT INTO users (uuid, username, email, password) VALUES ('u1', 'alice', 'alice@exemple.fr', 'hash')`,
+		`INSERT INTO posts (user_id, title, content, status, created_at) VALUES (1, 'Ancien', 'Post', 'pending', '2020-01-01 10:00:00')`,
+		`INSERT INTO posts (user_id, title, content, status) VALUES (1, 'Nouveau', 'Post', 'pending')`,
+		`INSERT INTO comments (post_id, user_id, content, status, created_at) VALUES (1, 1, 'Sans statut', '', '2020-01-01 11:00:00')`,
+	}
+	for _, insert := range inserts {
+		if _, err := db.Exec(insert); err != nil {
+			t.Fatalf("failed to insert legacy data: %v", err)
+		}
+	}
+	if _, err := db.Exec("UPDATE posts SET created_at = datetime('now', '+1 minute') WHERE id = 2"); err != nil {
+		t.Fatalf("failed to date post: %v", err)
+	}
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() failed: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT status FROM posts WHERE id = 1", "approved"},
+		{"SELECT status FROM posts WHERE id = 2", "pending"},
+		{"SELECT status FROM comments WHERE id = 1", "approved"},
+	}
+	for _, tt := range tests {
+		var status string
+		if err := db.QueryRow(tt.query).Scan(&status); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if status != tt.want {
+			t.Errorf("%s = %q, want %q", tt.query, status, tt.want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+-- Suppression des tables du schéma initial, dépendances d'abord
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS activities;
+DROP TABLE IF EXISTS likes;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS users;
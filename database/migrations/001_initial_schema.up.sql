@@ -135,34 +135,3 @@ CREATE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
 CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
 CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
-
-
-
-
--- add notif (a commenter )
-
--- -- Ajout des colonnes pour le système de notifications dans la table activities
--- ALTER TABLE activities ADD COLUMN recipient_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
--- ALTER TABLE activities ADD COLUMN is_read BOOLEAN DEFAULT 0;
-
--- -- Mise à jour des données existantes : 
--- -- Pour les activités de type comment, like et dislike, le recipient_id est le propriétaire du post
--- UPDATE activities 
--- SET recipient_id = (
---     SELECT user_id FROM posts WHERE id = activities.target_id
--- )
--- WHERE type IN ('comment', 'like', 'dislike');
-
--- -- Pour les activités de type update_profile, le recipient_id est l'utilisateur lui-même
--- UPDATE activities 
--- SET recipient_id = user_id
--- WHERE type = 'update_profile';
-
--- -- Pour les activités de type create_post, le recipient_id est l'utilisateur lui-même
--- UPDATE activities 
--- SET recipient_id = user_id
--- WHERE type = 'create_post';
-
--- -- Création des index pour optimiser les requêtes
--- CREATE INDEX IF NOT EXISTS idx_activities_recipient_id ON activities(recipient_id);
--- CREATE INDEX IF NOT EXISTS idx_activities_is_read ON activities(is_read);
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_activities_recipient_id;
+DROP INDEX IF EXISTS idx_activities_is_read;
+
+ALTER TABLE activities DROP COLUMN is_read;
+ALTER TABLE activities DROP COLUMN recipient_id;
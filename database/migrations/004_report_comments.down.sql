@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reports_comment_id;
+
+-- Les signalements de commentaires n'ont plus de cible sans cette colonne
+DELETE FROM reports WHERE comment_id IS NOT NULL;
+ALTER TABLE reports DROP COLUMN comment_id;
@@ -0,0 +1,4 @@
+-- Les signalements peuvent viser un commentaire précis
+ALTER TABLE reports ADD COLUMN comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_reports_comment_id ON reports(comment_id);
@@ -0,0 +1,3 @@
+-- Suspension des comptes
+ALTER TABLE users ADD COLUMN banned_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN ban_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_comments_parent_id;
+
+-- Les réponses sont rattachées directement au post
+ALTER TABLE comments DROP COLUMN parent_id;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_posts_post_type;
+
+ALTER TABLE posts DROP COLUMN accepted_comment_id;
+ALTER TABLE posts DROP COLUMN post_type;
@@ -0,0 +1,7 @@
+-- Type de post : discussion (par défaut) ou question
+ALTER TABLE posts ADD COLUMN post_type TEXT NOT NULL DEFAULT 'discussion';
+
+-- Réponse acceptée par l'auteur d'une question
+ALTER TABLE posts ADD COLUMN accepted_comment_id INTEGER REFERENCES comments(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_posts_post_type ON posts(post_type);
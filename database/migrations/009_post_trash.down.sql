@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+
+ALTER TABLE posts DROP COLUMN status_before_delete;
+ALTER TABLE posts DROP COLUMN deleted_at;
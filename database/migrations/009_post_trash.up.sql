@@ -0,0 +1,5 @@
+-- Corbeille : date de suppression et statut à rétablir lors d'une restauration
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN status_before_delete TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts(deleted_at);
@@ -0,0 +1,3 @@
+-- Les index plein texte n'appartiennent pas aux migrations : ils sont gérés
+-- par SetupFullTextSearch et survivent à l'annulation de celle-ci.
+SELECT 1;
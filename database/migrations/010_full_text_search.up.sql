@@ -0,0 +1,5 @@
+-- Les index plein texte dépendent du module FTS5, absent de certaines
+-- compilations de SQLite : ils sont créés hors de la chaîne des migrations,
+-- par SetupFullTextSearch (database/fulltext.sql), pour que les migrations
+-- suivantes s'appliquent toujours dans l'ordre.
+SELECT 1;
@@ -0,0 +1,23 @@
+-- Une seule réaction par utilisateur et par post ou commentaire.
+-- La contrainte UNIQUE(user_id, post_id, comment_id) de la table likes ne
+-- s'applique pas : l'une des deux colonnes est toujours NULL, et SQLite
+-- considère les NULL comme distincts.
+
+-- Suppression des doublons existants, en gardant la réaction la plus récente
+DELETE FROM likes WHERE post_id IS NOT NULL AND id NOT IN (
+    SELECT MAX(id) FROM likes WHERE post_id IS NOT NULL GROUP BY user_id, post_id
+);
+DELETE FROM likes WHERE comment_id IS NOT NULL AND id NOT IN (
+    SELECT MAX(id) FROM likes WHERE comment_id IS NOT NULL GROUP BY user_id, comment_id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_likes_user_post ON likes(user_id, post_id) WHERE post_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_likes_user_comment ON likes(user_id, comment_id) WHERE comment_id IS NOT NULL;
+
+-- Compteurs recalculés à partir des réactions
+UPDATE posts SET
+    like_count = (SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id AND is_like = 1),
+    dislike_count = (SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id AND is_like = 0);
+UPDATE comments SET
+    like_count = (SELECT COUNT(*) FROM likes WHERE likes.comment_id = comments.id AND is_like = 1),
+    dislike_count = (SELECT COUNT(*) FROM likes WHERE likes.comment_id = comments.id AND is_like = 0);
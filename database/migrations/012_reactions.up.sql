@@ -0,0 +1,28 @@
+-- Réactions généralisées : la table likes ne savait stocker qu'un booléen.
+-- Le type est la clé d'une réaction du registre (like, dislike, helpful...).
+-- Un utilisateur peut poser plusieurs réactions différentes sur un même post,
+-- mais une seule fois chacune ; like et dislike restent exclusifs (géré par le store).
+CREATE TABLE IF NOT EXISTS reactions (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    post_id INTEGER,
+    comment_id INTEGER,
+    type TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    CHECK ((post_id IS NULL AND comment_id IS NOT NULL) OR (post_id IS NOT NULL AND comment_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_user_post ON reactions(user_id, post_id, type) WHERE post_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_user_comment ON reactions(user_id, comment_id, type) WHERE comment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_reactions_post ON reactions(post_id, type) WHERE post_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_reactions_comment ON reactions(comment_id, type) WHERE comment_id IS NOT NULL;
+
+-- Reprise des likes et dislikes existants
+INSERT INTO reactions (id, user_id, post_id, comment_id, type, created_at)
+SELECT id, user_id, post_id, comment_id, CASE WHEN is_like THEN 'like' ELSE 'dislike' END, created_at
+FROM likes;
+
+DROP TABLE likes;
@@ -0,0 +1,3 @@
+-- Préférence de confidentialité : les dislikes de l'utilisateur restent anonymes
+-- dans les listes « qui a réagi »
+ALTER TABLE users ADD COLUMN anonymous_dislikes BOOLEAN NOT NULL DEFAULT 0;
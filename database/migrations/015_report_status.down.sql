@@ -0,0 +1,2 @@
+ALTER TABLE comments DROP COLUMN status_before_report;
+ALTER TABLE posts DROP COLUMN status_before_report;
@@ -0,0 +1,3 @@
+-- Statut à rétablir quand un signalement est classé ou un contenu masqué rétabli
+ALTER TABLE posts ADD COLUMN status_before_report TEXT NOT NULL DEFAULT '';
+ALTER TABLE comments ADD COLUMN status_before_report TEXT NOT NULL DEFAULT '';
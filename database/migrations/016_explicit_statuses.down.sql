@@ -0,0 +1,2 @@
+-- Migration de données : les anciens statuts ne sont pas conservés
+SELECT 1;
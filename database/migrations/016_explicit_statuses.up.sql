@@ -0,0 +1,14 @@
+-- Statuts hérités de l'ancien schéma, où tout contenu était public : les
+-- commentaires enregistrés sans statut et les contenus « pending » (valeur par
+-- défaut des colonnes) antérieurs à l'adoption des migrations deviennent
+-- explicitement publics. Les contenus en attente créés depuis, par la
+-- pré-modération, gardent leur statut.
+UPDATE posts SET status = 'approved'
+WHERE status IS NULL OR status = ''
+    OR (status = 'pending'
+        AND julianday(created_at) < (SELECT julianday(applied_at) FROM schema_migrations WHERE version = 1));
+
+UPDATE comments SET status = 'approved'
+WHERE status IS NULL OR status = ''
+    OR (status = 'pending'
+        AND julianday(created_at) < (SELECT julianday(applied_at) FROM schema_migrations WHERE version = 1));
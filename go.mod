@@ -13,3 +13,10 @@ require (
 require github.com/gomarkdown/markdown v0.0.0-20250311123330-531bef5e742b
 
 require github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
+
+require (
+	github.com/alecthomas/chroma/v2 v2.14.0
+	golang.org/x/net v0.38.0
+)
+
+require github.com/dlclark/regexp2 v1.11.0 // indirect
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"forum/models"
+
+	"github.com/gorilla/mux"
+)
+
+// AcceptAnswer marque un commentaire comme réponse acceptée d'une question.
+// Seuls l'auteur de la question et les modérateurs peuvent le faire.
+func (h *PostHandler) AcceptAnswer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+	commentID, err := strconv.ParseInt(vars["commentID"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de commentaire invalide", http.StatusBadRequest)
+		return
+	}
+
+	post, ok := h.questionForAnswer(w, r, postID)
+	if !ok {
+		return
+	}
+
+	comment, err := h.CommentStore.GetByID(commentID)
+	if err != nil || comment.PostID != post.ID {
+		http.Error(w, "Commentaire non trouvé", http.StatusNotFound)
+		return
+	}
+	if !comment.Status.IsPublic() {
+		http.Error(w, "Seul un commentaire publié peut être accepté", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.PostStore.SetAcceptedAnswer(post.ID, comment.ID); err != nil {
+		http.Error(w, "Erreur lors de l'acceptation de la réponse", http.StatusInternalServerError)
+		return
+	}
+
+	// Prévenir l'auteur de la réponse, sauf s'il l'accepte lui-même
+	if acceptor := GetUserIDFromRequest(r); comment.UserID != acceptor && post.AcceptedCommentID != comment.ID {
+		activity := &models.Activity{
+			UserID:      acceptor,
+			RecipientID: comment.UserID,
+			Type:        models.ActivityAnswer,
+			TargetID:    post.ID,
+			CreatedAt:   time.Now(),
+			Content:     "a accepté votre réponse à la question",
+			IsRead:      false,
+		}
+		if err := h.ActivityStore.Create(activity); err != nil {
+			log.Printf("Failed to create answer notification: %v", err)
+		}
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/post/%d#comment-%d", post.ID, comment.ID), http.StatusSeeOther)
+}
+
+// UnacceptAnswer retire la réponse acceptée d'une question
+func (h *PostHandler) UnacceptAnswer(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+
+	post, ok := h.questionForAnswer(w, r, postID)
+	if !ok {
+		return
+	}
+
+	if err := h.PostStore.SetAcceptedAnswer(post.ID, 0); err != nil {
+		http.Error(w, "Erreur lors de la mise à jour de la question", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/post/%d", post.ID), http.StatusSeeOther)
+}
+
+// questionForAnswer charge la question et vérifie que l'utilisateur peut en choisir la réponse
+func (h *PostHandler) questionForAnswer(w http.ResponseWriter, r *http.Request, postID int64) (*models.Post, bool) {
+	if GetUserIDFromRequest(r) == 0 {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, false
+	}
+
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return nil, false
+	}
+	if !canEditPost(r, post) {
+		http.Error(w, "Vous n'êtes pas autorisé à choisir la réponse de cette question", http.StatusForbidden)
+		return nil, false
+	}
+	if !post.IsQuestion() {
+		http.Error(w, "Ce post n'est pas une question", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return post, true
+}
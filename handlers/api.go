@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"forum/models"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// APIHandler sert l'API JSON versionnée /api/v1, destinée aux clients mobiles
+// et aux bots. Toutes les réponses suivent la même enveloppe :
+//
+//	{"data": ..., "next_cursor": "..."}      en cas de succès
+//	{"error": {"code": "...", "message": "..."}}  en cas d'erreur
+//
+// Les listes sont paginées par curseur : next_cursor, absent sur la dernière
+// page, est à renvoyer tel quel dans le paramètre cursor.
+type APIHandler struct {
+	PostStore     *models.PostStore
+	TagStore      *models.TagStore
+	CommentStore  *models.CommentStore
+	UserStore     *models.UserStore
+	ReactionStore *models.ReactionStore
+	ActivityStore *models.ActivityStore
+	RevisionStore *models.RevisionStore
+	Moderation    *models.ModerationPolicy
+}
+
+// NewAPIHandler crée une nouvelle instance de APIHandler
+func NewAPIHandler(postStore *models.PostStore, tagStore *models.TagStore, commentStore *models.CommentStore, userStore *models.UserStore, reactionStore *models.ReactionStore, activityStore *models.ActivityStore, revisionStore *models.RevisionStore, moderation *models.ModerationPolicy) *APIHandler {
+	return &APIHandler{
+		PostStore:     postStore,
+		TagStore:      tagStore,
+		CommentStore:  commentStore,
+		UserStore:     userStore,
+		ReactionStore: reactionStore,
+		ActivityStore: activityStore,
+		RevisionStore: revisionStore,
+		Moderation:    moderation,
+	}
+}
+
+// Taille des pages de l'API : paramètre limit, entre 1 et apiMaxLimit
+const (
+	apiDefaultLimit = 20
+	apiMaxLimit     = 100
+)
+
+// Codes d'erreur de l'API
+const (
+	apiErrBadRequest   = "bad_request"
+	apiErrUnauthorized = "unauthorized"
+	apiErrForbidden    = "forbidden"
+	apiErrNotFound     = "not_found"
+	apiErrConflict     = "conflict"
+	apiErrInternal     = "internal_error"
+)
+
+// RegisterAPIRoutes enregistre les routes de l'API v1
+func RegisterAPIRoutes(r *mux.Router, h *APIHandler) {
+	api := r.PathPrefix("/api/v1").Subrouter()
+
+	api.HandleFunc("/posts", h.ListPosts).Methods("GET")
+	api.HandleFunc("/posts", h.CreatePost).Methods("POST")
+	api.HandleFunc("/posts/{id:[0-9]+}", h.GetPost).Methods("GET")
+	api.HandleFunc("/posts/{id:[0-9]+}", h.UpdatePost).Methods("PATCH")
+	api.HandleFunc("/posts/{id:[0-9]+}", h.DeletePost).Methods("DELETE")
+
+	api.HandleFunc("/posts/{id:[0-9]+}/comments", h.ListComments).Methods("GET")
+	api.HandleFunc("/posts/{id:[0-9]+}/comments", h.CreateComment).Methods("POST")
+	api.HandleFunc("/comments/{id:[0-9]+}", h.GetComment).Methods("GET")
+	api.HandleFunc("/comments/{id:[0-9]+}", h.UpdateComment).Methods("PATCH")
+	api.HandleFunc("/comments/{id:[0-9]+}", h.DeleteComment).Methods("DELETE")
+
+	api.HandleFunc("/tags", h.ListTags).Methods("GET")
+	api.HandleFunc("/tags", h.CreateTag).Methods("POST")
+	api.HandleFunc("/tags/{id:[0-9]+}", h.GetTag).Methods("GET")
+	api.HandleFunc("/tags/{id:[0-9]+}", h.UpdateTag).Methods("PATCH")
+	api.HandleFunc("/tags/{id:[0-9]+}", h.DeleteTag).Methods("DELETE")
+
+	api.HandleFunc("/users", h.ListUsers).Methods("GET")
+	api.HandleFunc("/users", h.CreateUser).Methods("POST")
+	api.HandleFunc("/users/me", h.GetMe).Methods("GET")
+	api.HandleFunc("/users/me", h.UpdateMe).Methods("PATCH")
+	api.HandleFunc("/users/{id:[0-9]+}", h.GetUser).Methods("GET")
+
+	api.HandleFunc("/notifications", h.ListNotifications).Methods("GET")
+	api.HandleFunc("/notifications/{id:[0-9]+}", h.GetNotification).Methods("GET")
+	api.HandleFunc("/notifications/{id:[0-9]+}", h.UpdateNotification).Methods("PATCH")
+	api.HandleFunc("/notifications/{id:[0-9]+}", h.DeleteNotification).Methods("DELETE")
+
+	// Les routes inconnues de l'API répondent aussi dans l'enveloppe JSON
+	api.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Ressource inconnue")
+	})
+}
+
+// apiError est le contenu de l'enveloppe d'erreur
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiResponse est l'enveloppe de toutes les réponses de l'API
+type apiResponse struct {
+	Data       interface{} `json:"data,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Error      *apiError   `json:"error,omitempty"`
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, response apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Erreur lors de l'écriture de la réponse API: %v", err)
+	}
+}
+
+// writeAPIData renvoie une ressource
+func writeAPIData(w http.ResponseWriter, status int, data interface{}) {
+	writeAPIResponse(w, status, apiResponse{Data: data})
+}
+
+// writeAPIList renvoie une page d'une liste ; nextCursor est vide sur la dernière page
+func writeAPIList(w http.ResponseWriter, data interface{}, nextCursor string) {
+	writeAPIResponse(w, http.StatusOK, apiResponse{Data: data, NextCursor: nextCursor})
+}
+
+// writeAPIError renvoie une erreur dans l'enveloppe de l'API
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIResponse(w, status, apiResponse{Error: &apiError{Code: code, Message: message}})
+}
+
+// writeAPIInternalError journalise l'erreur et renvoie une erreur générique
+func writeAPIInternalError(w http.ResponseWriter, context string, err error) {
+	log.Printf("API: %s: %v", context, err)
+	writeAPIError(w, http.StatusInternalServerError, apiErrInternal, "Erreur serveur")
+}
+
+// apiUser retourne l'utilisateur connecté, ou renvoie une erreur 401
+func apiUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user := GetCurrentUser(r)
+	if user == nil {
+		writeAPIError(w, http.StatusUnauthorized, apiErrUnauthorized, "Authentification requise")
+		return nil, false
+	}
+	return user, true
+}
+
+// apiModerator vérifie que l'utilisateur connecté est modérateur
+func apiModerator(w http.ResponseWriter, r *http.Request) bool {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return false
+	}
+	if !user.IsModerator() {
+		writeAPIError(w, http.StatusForbidden, apiErrForbidden, "Action réservée aux modérateurs")
+		return false
+	}
+	return true
+}
+
+// apiID lit l'identifiant de la ressource dans l'URL
+func apiID(r *http.Request) int64 {
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	return id
+}
+
+// decodeAPIBody lit le corps JSON de la requête ; les champs inconnus sont refusés
+func decodeAPIBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Corps JSON invalide: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// apiPage lit les paramètres limit et cursor d'une liste. Le curseur est
+// décodé dans cursor ; ok est faux si une erreur a déjà été renvoyée.
+func apiPage(w http.ResponseWriter, r *http.Request, cursor interface{}) (limit int, hasCursor, ok bool) {
+	query := r.URL.Query()
+
+	limit = apiDefaultLimit
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > apiMaxLimit {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "limit doit être compris entre 1 et "+strconv.Itoa(apiMaxLimit))
+			return 0, false, false
+		}
+		limit = n
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		if err := decodeCursor(value, cursor); err != nil {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Curseur invalide")
+			return 0, false, false
+		}
+		hasCursor = true
+	}
+
+	return limit, hasCursor, true
+}
+
+// encodeCursor rend un curseur opaque pour les clients : du JSON en base64
+func encodeCursor(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Erreur lors de l'encodage d'un curseur: %v", err)
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor décode un curseur produit par encodeCursor
+func decodeCursor(cursor string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"forum/models"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// apiCommentInput est le corps des requêtes de création et de modification
+// d'un commentaire. parent_id désigne le commentaire auquel on répond.
+type apiCommentInput struct {
+	Content  string `json:"content"`
+	ParentID int64  `json:"parent_id"`
+}
+
+// ListComments liste les commentaires d'un post dans l'ordre de publication.
+// Les réponses sont à plat : parent_id permet de reconstruire le fil.
+func (h *APIHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+
+	var afterID int64
+	limit, _, ok := apiPage(w, r, &afterID)
+	if !ok {
+		return
+	}
+
+	comments, err := h.CommentStore.ListByPostID(post.ID, afterID, limit+1, GetCurrentUser(r))
+	if err != nil {
+		writeAPIInternalError(w, "liste des commentaires", err)
+		return
+	}
+
+	next := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		next = encodeCursor(comments[limit-1].ID)
+	}
+
+	h.prepareComments(comments)
+	writeAPIList(w, comments, next)
+}
+
+// GetComment renvoie un commentaire
+func (h *APIHandler) GetComment(w http.ResponseWriter, r *http.Request) {
+	comment, _, ok := h.visibleComment(w, r)
+	if !ok {
+		return
+	}
+	h.prepareComments([]*models.Comment{comment})
+	writeAPIData(w, http.StatusOK, comment)
+}
+
+// CreateComment commente un post ou répond à un commentaire, avec la même
+// pré-modération et les mêmes notifications que sur le site
+func (h *APIHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	if post.Status == models.PostStatusDeleted {
+		writeAPIError(w, http.StatusConflict, apiErrConflict, "Ce post est dans la corbeille")
+		return
+	}
+
+	var input apiCommentInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le contenu du commentaire est obligatoire")
+		return
+	}
+
+	var parent *models.Comment
+	if input.ParentID != 0 {
+		var err error
+		parent, err = h.CommentStore.GetByID(input.ParentID)
+		if err != nil || parent.PostID != post.ID || parent.Status == models.PostStatusDeleted {
+			writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Commentaire parent non trouvé")
+			return
+		}
+	}
+
+	comment := &models.Comment{
+		PostID:   post.ID,
+		ParentID: input.ParentID,
+		UserID:   user.ID,
+		Content:  input.Content,
+		Status:   h.Moderation.InitialStatus(user),
+	}
+	if err := h.CommentStore.Create(comment); err != nil {
+		writeAPIInternalError(w, "création du commentaire", err)
+		return
+	}
+
+	// Les notifications d'un commentaire en attente sont envoyées à sa validation
+	if comment.Status != models.StatusPending {
+		notifyNewComment(h.ActivityStore, post, parent, comment)
+	}
+
+	activity := &models.Activity{
+		UserID:      user.ID,
+		RecipientID: user.ID,
+		Type:        models.ActivityComment,
+		TargetID:    post.ID,
+		CreatedAt:   time.Now(),
+		Content:     "a commenté sur un post de",
+		IsRead:      true,
+	}
+	if err := h.ActivityStore.Create(activity); err != nil {
+		log.Printf("Failed to create activity record: %v", err)
+	}
+
+	h.writeComment(w, http.StatusCreated, comment.ID)
+}
+
+// UpdateComment modifie le contenu d'un commentaire, par son auteur ou un modérateur
+func (h *APIHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := h.editableComment(w, r)
+	if !ok {
+		return
+	}
+
+	var input apiCommentInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le contenu du commentaire est obligatoire")
+		return
+	}
+	if input.ParentID != 0 && input.ParentID != comment.ParentID {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Un commentaire ne peut pas être déplacé dans le fil")
+		return
+	}
+
+	if input.Content != comment.Content {
+		comment.Content = input.Content
+		comment.Status = h.Moderation.EditStatus(GetCurrentUser(r), comment.Status)
+	}
+	if err := h.CommentStore.Update(comment); err != nil {
+		writeAPIInternalError(w, "mise à jour du commentaire", err)
+		return
+	}
+
+	h.writeComment(w, http.StatusOK, comment.ID)
+}
+
+// DeleteComment supprime un commentaire ; il reste affiché comme supprimé dans le fil
+func (h *APIHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := h.editableComment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteComment(h.CommentStore, h.PostStore, comment); err != nil {
+		writeAPIInternalError(w, "suppression du commentaire", err)
+		return
+	}
+
+	h.writeComment(w, http.StatusOK, comment.ID)
+}
+
+// visibleComment charge le commentaire de l'URL et son post s'il est visible
+// par l'utilisateur, avec les règles du fil de discussion
+func (h *APIHandler) visibleComment(w http.ResponseWriter, r *http.Request) (*models.Comment, *models.Post, bool) {
+	comment, err := h.CommentStore.GetByID(apiID(r))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Commentaire non trouvé")
+		return nil, nil, false
+	}
+
+	post, err := h.PostStore.GetByID(comment.PostID)
+	if err != nil || (!post.Status.IsPublic() && !canEditPost(r, post)) || !comment.VisibleTo(GetCurrentUser(r)) {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Commentaire non trouvé")
+		return nil, nil, false
+	}
+	return comment, post, true
+}
+
+// editableComment charge le commentaire de l'URL et vérifie que l'utilisateur peut le modifier
+func (h *APIHandler) editableComment(w http.ResponseWriter, r *http.Request) (*models.Comment, bool) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	comment, post, ok := h.visibleComment(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !comment.CanEdit(user.ID, user.Role) {
+		writeAPIError(w, http.StatusForbidden, apiErrForbidden, "Vous n'êtes pas autorisé à modifier ce commentaire")
+		return nil, false
+	}
+	if comment.Status == models.PostStatusDeleted {
+		writeAPIError(w, http.StatusConflict, apiErrConflict, "Ce commentaire a été supprimé")
+		return nil, false
+	}
+	if post.Status == models.PostStatusDeleted {
+		writeAPIError(w, http.StatusConflict, apiErrConflict, "Ce post est dans la corbeille")
+		return nil, false
+	}
+	return comment, true
+}
+
+// writeComment relit le commentaire enregistré et le renvoie
+func (h *APIHandler) writeComment(w http.ResponseWriter, status int, commentID int64) {
+	comment, err := h.CommentStore.GetByID(commentID)
+	if err != nil {
+		writeAPIInternalError(w, "lecture du commentaire", err)
+		return
+	}
+	h.prepareComments([]*models.Comment{comment})
+	writeAPIData(w, status, comment)
+}
+
+// prepareComments charge les compteurs de réactions des commentaires
+func (h *APIHandler) prepareComments(comments []*models.Comment) {
+	ids := make([]int64, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+
+	counts, err := h.ReactionStore.CountsFor(models.CommentReactions, ids)
+	if err != nil {
+		log.Printf("Erreur lors du chargement des réactions: %v", err)
+		return
+	}
+	for _, comment := range comments {
+		comment.Reactions = counts[comment.ID]
+	}
+}
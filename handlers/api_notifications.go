@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"forum/models"
+	"net/http"
+)
+
+// apiNotificationInput est le corps de la modification d'une notification
+type apiNotificationInput struct {
+	IsRead bool `json:"is_read"`
+}
+
+// ListNotifications liste les notifications de l'utilisateur connecté, les
+// plus récentes d'abord
+func (h *APIHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	var beforeID int64
+	limit, _, ok := apiPage(w, r, &beforeID)
+	if !ok {
+		return
+	}
+
+	notifications, err := h.ActivityStore.GetNotificationsBefore(user.ID, beforeID, limit+1)
+	if err != nil {
+		writeAPIInternalError(w, "liste des notifications", err)
+		return
+	}
+
+	next := ""
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		next = encodeCursor(notifications[limit-1].ID)
+	}
+	writeAPIList(w, notifications, next)
+}
+
+// GetNotification renvoie une notification de l'utilisateur connecté
+func (h *APIHandler) GetNotification(w http.ResponseWriter, r *http.Request) {
+	notification, ok := h.ownNotification(w, r)
+	if !ok {
+		return
+	}
+	writeAPIData(w, http.StatusOK, notification)
+}
+
+// UpdateNotification marque une notification comme lue ou non lue
+func (h *APIHandler) UpdateNotification(w http.ResponseWriter, r *http.Request) {
+	notification, ok := h.ownNotification(w, r)
+	if !ok {
+		return
+	}
+
+	var input apiNotificationInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+
+	if err := h.ActivityStore.SetRead(notification.ID, input.IsRead); err != nil {
+		writeAPIInternalError(w, "mise à jour de la notification", err)
+		return
+	}
+	notification.IsRead = input.IsRead
+	writeAPIData(w, http.StatusOK, notification)
+}
+
+// DeleteNotification supprime une notification de l'utilisateur connecté
+func (h *APIHandler) DeleteNotification(w http.ResponseWriter, r *http.Request) {
+	notification, ok := h.ownNotification(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ActivityStore.Delete(notification.ID); err != nil {
+		writeAPIInternalError(w, "suppression de la notification", err)
+		return
+	}
+	writeAPIData(w, http.StatusOK, notification)
+}
+
+// ownNotification charge la notification de l'URL si elle est destinée à
+// l'utilisateur connecté ; celles des autres sont inconnues pour lui
+func (h *APIHandler) ownNotification(w http.ResponseWriter, r *http.Request) (*models.Activity, bool) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	notification, err := h.ActivityStore.GetByID(apiID(r))
+	if err != nil || notification.RecipientID != user.ID || notification.UserID == user.ID {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Notification non trouvée")
+		return nil, false
+	}
+	return notification, true
+}
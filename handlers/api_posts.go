@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"fmt"
+	"forum/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tris proposés par l'API. La pertinence et la popularité récente ne se
+// prêtent pas à la pagination par curseur.
+var apiPostSorts = map[string]bool{
+	"date": true, "likes": true, "dislikes": true, "score": true,
+	"comments": true, "activity": true, "title": true,
+}
+
+// apiPostCursor est le curseur des listes de posts. Le tri y est conservé :
+// un curseur ne peut pas être réutilisé avec un autre tri.
+type apiPostCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	models.PostCursor
+}
+
+// validCursorValue vérifie le type de la valeur d'un curseur de posts : une
+// chaîne pour le tri par titre, un nombre pour les autres tris (les dates sont
+// en jours juliens)
+func validCursorValue(sortBy string, value interface{}) bool {
+	if sortBy == "title" {
+		_, ok := value.(string)
+		return ok
+	}
+	_, ok := value.(float64)
+	return ok
+}
+
+// apiPostInput est le corps des requêtes de création et de modification d'un
+// post. Les champs absents d'une modification restent inchangés.
+type apiPostInput struct {
+	Title   *string   `json:"title"`
+	Content *string   `json:"content"`
+	Type    *string   `json:"type"`
+	Tags    *[]string `json:"tags"` // Noms des tags, créés si nécessaire
+}
+
+// ListPosts liste les posts publics. Paramètres : q, author (nom) ou user_id,
+// tag, xtag et match, type, has_image, answered, min_likes, min_comments,
+// from et to (AAAA-MM-JJ), status (modérateurs), sort, order, limit et cursor.
+func (h *APIHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	currentUser := GetCurrentUser(r)
+
+	var cursor apiPostCursor
+	limit, hasCursor, ok := apiPage(w, r, &cursor)
+	if !ok {
+		return
+	}
+
+	filter := models.PostFilter{
+		Statuses: models.PublicPostStatuses,
+		Search:   strings.TrimSpace(query.Get("q")),
+		Cursor:   &cursor.PostCursor,
+	}
+	// Un post de plus que demandé indique l'existence d'une page suivante
+	filter.Pagination.PerPage = limit + 1
+
+	filter.SortBy = query.Get("sort")
+	if filter.SortBy == "" {
+		filter.SortBy = "date"
+	}
+	if !apiPostSorts[filter.SortBy] {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Tri inconnu: "+filter.SortBy)
+		return
+	}
+	filter.SortOrder = query.Get("order")
+	if filter.SortOrder == "" {
+		filter.SortOrder = "desc"
+	}
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "order doit valoir asc ou desc")
+		return
+	}
+	if hasCursor && (cursor.Sort != filter.SortBy || cursor.Order != filter.SortOrder) {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Ce curseur correspond à un autre tri")
+		return
+	}
+	if hasCursor && !cursor.IsZero() && !validCursorValue(filter.SortBy, cursor.Value) {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Curseur invalide")
+		return
+	}
+
+	if authorName := strings.TrimSpace(query.Get("author")); authorName != "" {
+		author, err := h.UserStore.GetByUsername(authorName)
+		if err != nil {
+			writeAPIList(w, []*models.Post{}, "")
+			return
+		}
+		filter.UserID = author.ID
+	}
+	if value := query.Get("user_id"); value != "" {
+		userID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "user_id invalide")
+			return
+		}
+		filter.UserID = userID
+	}
+
+	parseTagSelection(query, &filter)
+
+	if value := query.Get("type"); value != "" {
+		switch postType := models.PostType(value); postType {
+		case models.PostTypeDiscussion, models.PostTypeQuestion:
+			filter.Type = postType
+		default:
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Type de post inconnu: "+value)
+			return
+		}
+	}
+
+	filter.HasImage = query.Get("has_image") == "1"
+	filter.HasAcceptedAnswer = query.Get("answered") == "1"
+
+	for param, dest := range map[string]*int{"min_likes": &filter.MinLikes, "min_comments": &filter.MinComments} {
+		if value := query.Get(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, param+" invalide")
+				return
+			}
+			*dest = n
+		}
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, err := time.ParseInLocation(searchDateLayout, value, time.Local)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "from doit être au format AAAA-MM-JJ")
+			return
+		}
+		filter.DateFrom = from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := time.ParseInLocation(searchDateLayout, value, time.Local)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "to doit être au format AAAA-MM-JJ")
+			return
+		}
+		// La date de fin est incluse : jusqu'à la fin de la journée
+		filter.DateTo = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	// Les modérateurs peuvent lister les contenus non publics
+	if value := query.Get("status"); value != "" {
+		if currentUser == nil || !currentUser.IsModerator() {
+			writeAPIError(w, http.StatusForbidden, apiErrForbidden, "Le filtre par statut est réservé aux modérateurs")
+			return
+		}
+		known := false
+		for _, allowed := range searchStatuses {
+			known = known || models.PostStatus(value) == allowed.Value
+		}
+		if !known {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Statut inconnu: "+value)
+			return
+		}
+		filter.Status = models.PostStatus(value)
+		filter.Statuses = nil
+	}
+
+	posts, err := h.PostStore.FilterPosts(filter)
+	if err != nil {
+		writeAPIInternalError(w, "liste des posts", err)
+		return
+	}
+
+	next := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1].Cursor()
+		next = encodeCursor(apiPostCursor{Sort: filter.SortBy, Order: filter.SortOrder, PostCursor: *last})
+	}
+	if posts == nil {
+		posts = []*models.Post{}
+	}
+
+	h.preparePosts(posts)
+	writeAPIList(w, posts, next)
+}
+
+// GetPost renvoie un post, avec ses tags et ses réactions
+func (h *APIHandler) GetPost(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return
+	}
+	h.preparePosts([]*models.Post{post})
+	writeAPIData(w, http.StatusOK, post)
+}
+
+// CreatePost publie un post, soumis à la pré-modération comme sur le site
+func (h *APIHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input apiPostInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+	if input.Title == nil || input.Content == nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le titre et le contenu sont obligatoires")
+		return
+	}
+
+	post := &models.Post{
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+		Status:    h.Moderation.InitialStatus(user),
+		Type:      models.PostTypeDiscussion,
+	}
+	if !applyPostInput(w, post, input) {
+		return
+	}
+
+	if err := h.PostStore.Create(post); err != nil {
+		writeAPIInternalError(w, "création du post", err)
+		return
+	}
+	if input.Tags != nil {
+		h.setPostTags(post.ID, *input.Tags)
+	}
+
+	activity := &models.Activity{
+		UserID:      user.ID,
+		RecipientID: user.ID,
+		Type:        models.ActivityCreatePost,
+		TargetID:    post.ID,
+		CreatedAt:   time.Now(),
+		Content:     fmt.Sprintf("a créé un nouveau post: %s", post.Title),
+		IsRead:      true,
+	}
+	h.ActivityStore.Create(activity)
+
+	h.writePost(w, http.StatusCreated, post.ID)
+}
+
+// UpdatePost modifie un post, par son auteur ou un modérateur. Les changements
+// de titre ou de contenu sont conservés dans l'historique des versions.
+func (h *APIHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	post, ok := h.editablePost(w, r)
+	if !ok {
+		return
+	}
+
+	var input apiPostInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+
+	before := *post
+	if !applyPostInput(w, post, input) {
+		return
+	}
+	if before.Title != post.Title || before.Content != post.Content {
+		post.Status = h.Moderation.EditStatus(user, post.Status)
+	}
+
+	if err := h.PostStore.UpdateWithRevision(&before, post, user.ID); err != nil {
+		writeAPIInternalError(w, "mise à jour du post", err)
+		return
+	}
+	if input.Tags != nil {
+		h.PostStore.RemoveAllTags(post.ID)
+		h.setPostTags(post.ID, *input.Tags)
+	}
+
+	h.writePost(w, http.StatusOK, post.ID)
+}
+
+// DeletePost met un post à la corbeille ; il reste restaurable depuis le site
+func (h *APIHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	if _, ok := apiUser(w, r); !ok {
+		return
+	}
+
+	post, ok := h.editablePost(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.PostStore.Delete(post.ID); err != nil {
+		writeAPIInternalError(w, "suppression du post", err)
+		return
+	}
+
+	h.writePost(w, http.StatusOK, post.ID)
+}
+
+// applyPostInput reporte les champs renseignés sur le post et les valide
+func applyPostInput(w http.ResponseWriter, post *models.Post, input apiPostInput) bool {
+	if input.Title != nil {
+		post.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Content != nil {
+		post.Content = *input.Content
+	}
+	if input.Type != nil {
+		switch postType := models.PostType(*input.Type); postType {
+		case models.PostTypeDiscussion, models.PostTypeQuestion:
+			post.Type = postType
+		default:
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Type de post inconnu: "+*input.Type)
+			return false
+		}
+	}
+
+	if post.Title == "" || strings.TrimSpace(post.Content) == "" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le titre et le contenu sont obligatoires")
+		return false
+	}
+	if err := ValidateMath(post.Content); err != nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// setPostTags associe au post les tags nommés, en créant ceux qui n'existent pas
+func (h *APIHandler) setPostTags(postID int64, names []string) {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		tag, err := h.TagStore.CreateOrGet(name, "")
+		if err != nil {
+			log.Printf("Erreur lors de la création du tag %q: %v", name, err)
+			continue
+		}
+		h.PostStore.AddTag(postID, tag.ID)
+	}
+}
+
+// visiblePost charge le post de l'URL s'il est visible par l'utilisateur
+func (h *APIHandler) visiblePost(w http.ResponseWriter, r *http.Request) (*models.Post, bool) {
+	post, err := h.PostStore.GetByID(apiID(r))
+	if err != nil || (!post.Status.IsPublic() && !canEditPost(r, post)) {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Post non trouvé")
+		return nil, false
+	}
+	return post, true
+}
+
+// editablePost charge le post de l'URL et vérifie que l'utilisateur peut le modifier
+func (h *APIHandler) editablePost(w http.ResponseWriter, r *http.Request) (*models.Post, bool) {
+	post, ok := h.visiblePost(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !canEditPost(r, post) {
+		writeAPIError(w, http.StatusForbidden, apiErrForbidden, "Vous n'êtes pas autorisé à modifier ce post")
+		return nil, false
+	}
+	if post.Status == models.PostStatusDeleted {
+		writeAPIError(w, http.StatusConflict, apiErrConflict, "Ce post est dans la corbeille")
+		return nil, false
+	}
+	return post, true
+}
+
+// writePost relit le post enregistré et le renvoie
+func (h *APIHandler) writePost(w http.ResponseWriter, status int, postID int64) {
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil {
+		writeAPIInternalError(w, "lecture du post", err)
+		return
+	}
+	h.preparePosts([]*models.Post{post})
+	writeAPIData(w, status, post)
+}
+
+// preparePosts charge les tags et les compteurs de réactions des posts
+func (h *APIHandler) preparePosts(posts []*models.Post) {
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+		if err := post.LoadTags(h.TagStore); err != nil {
+			log.Printf("Erreur lors du chargement des tags du post %d: %v", post.ID, err)
+		}
+		if post.Tags == nil {
+			post.Tags = []*models.Tag{}
+		}
+	}
+
+	counts, err := h.ReactionStore.CountsFor(models.PostReactions, ids)
+	if err != nil {
+		log.Printf("Erreur lors du chargement des réactions: %v", err)
+		return
+	}
+	for _, post := range posts {
+		post.Reactions = counts[post.ID]
+	}
+}
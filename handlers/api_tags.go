@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"forum/models"
+	"net/http"
+	"strings"
+)
+
+// apiTagInput est le corps des requêtes de création et de modification d'un
+// tag. Les champs absents d'une modification restent inchangés.
+type apiTagInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// ListTags liste les tags par ordre alphabétique
+func (h *APIHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	var after string
+	limit, _, ok := apiPage(w, r, &after)
+	if !ok {
+		return
+	}
+
+	tags, err := h.TagStore.ListAfter(after, limit+1)
+	if err != nil {
+		writeAPIInternalError(w, "liste des tags", err)
+		return
+	}
+
+	next := ""
+	if len(tags) > limit {
+		tags = tags[:limit]
+		next = encodeCursor(tags[limit-1].Name)
+	}
+	writeAPIList(w, tags, next)
+}
+
+// GetTag renvoie un tag
+func (h *APIHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	tag, err := h.TagStore.GetByID(apiID(r))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Tag non trouvé")
+		return
+	}
+	writeAPIData(w, http.StatusOK, tag)
+}
+
+// CreateTag crée un tag (modérateurs). Les autres utilisateurs créent leurs
+// tags en les associant à leurs posts.
+func (h *APIHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	if !apiModerator(w, r) {
+		return
+	}
+
+	var input apiTagInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+
+	tag := &models.Tag{}
+	if !h.applyTagInput(w, tag, input) {
+		return
+	}
+	if tag.Name == "" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le nom du tag est obligatoire")
+		return
+	}
+
+	if err := h.TagStore.Create(tag); err != nil {
+		writeAPIInternalError(w, "création du tag", err)
+		return
+	}
+	h.writeTag(w, http.StatusCreated, tag.ID)
+}
+
+// UpdateTag renomme un tag ou modifie sa description (modérateurs)
+func (h *APIHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	if !apiModerator(w, r) {
+		return
+	}
+
+	tag, err := h.TagStore.GetByID(apiID(r))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Tag non trouvé")
+		return
+	}
+
+	var input apiTagInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+	if !h.applyTagInput(w, tag, input) {
+		return
+	}
+
+	if err := h.TagStore.Update(tag); err != nil {
+		writeAPIInternalError(w, "mise à jour du tag", err)
+		return
+	}
+	h.writeTag(w, http.StatusOK, tag.ID)
+}
+
+// DeleteTag supprime un tag et le retire de ses posts (modérateurs)
+func (h *APIHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	if !apiModerator(w, r) {
+		return
+	}
+
+	tag, err := h.TagStore.GetByID(apiID(r))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Tag non trouvé")
+		return
+	}
+
+	if err := h.TagStore.Delete(tag.ID); err != nil {
+		writeAPIInternalError(w, "suppression du tag", err)
+		return
+	}
+	writeAPIData(w, http.StatusOK, tag)
+}
+
+// applyTagInput reporte les champs renseignés sur le tag ; un nom déjà
+// utilisé par un autre tag est refusé
+func (h *APIHandler) applyTagInput(w http.ResponseWriter, tag *models.Tag, input apiTagInput) bool {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le nom du tag est obligatoire")
+			return false
+		}
+		if existing, err := h.TagStore.GetByName(name); err == nil && existing.ID != tag.ID {
+			writeAPIError(w, http.StatusConflict, apiErrConflict, "Un tag porte déjà ce nom")
+			return false
+		}
+		tag.Name = name
+	}
+	if input.Description != nil {
+		tag.Description = strings.TrimSpace(*input.Description)
+	}
+	return true
+}
+
+// writeTag relit le tag enregistré et le renvoie
+func (h *APIHandler) writeTag(w http.ResponseWriter, status int, tagID int64) {
+	tag, err := h.TagStore.GetByID(tagID)
+	if err != nil {
+		writeAPIInternalError(w, "lecture du tag", err)
+		return
+	}
+	writeAPIData(w, status, tag)
+}
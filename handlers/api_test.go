@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"forum/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		into  func() interface{}
+	}{
+		{"identifiant", int64(42), func() interface{} { return new(int64) }},
+		{"nom de tag", "génie logiciel", func() interface{} { return new(string) }},
+		{"post", models.PostCursor{Value: "2024-05-01", ID: 7}, func() interface{} { return new(models.PostCursor) }},
+		{"post avec valeur numérique", models.PostCursor{Value: float64(12), ID: 3}, func() interface{} { return new(models.PostCursor) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := encodeCursor(tt.value)
+			if cursor == "" {
+				t.Fatal("encodeCursor returned an empty cursor")
+			}
+
+			got := tt.into()
+			if err := decodeCursor(cursor, got); err != nil {
+				t.Fatalf("decodeCursor(%q) failed: %v", cursor, err)
+			}
+			if !reflect.DeepEqual(reflect.ValueOf(got).Elem().Interface(), tt.value) {
+				t.Errorf("decodeCursor(encodeCursor(%v)) = %v", tt.value, reflect.ValueOf(got).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsInvalidCursors(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"base64 invalide", "pas un curseur!"},
+		{"base64 standard avec remplissage", "NDI="},
+		{"JSON invalide", "bm9uLWpzb24"},
+		{"mauvais type", encodeCursor("texte")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id int64
+			if err := decodeCursor(tt.cursor, &id); err == nil {
+				t.Errorf("decodeCursor(%q) succeeded with %d, want an error", tt.cursor, id)
+			}
+		})
+	}
+}
+
+func TestAPIPage(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		wantOK        bool
+		wantLimit     int
+		wantHasCursor bool
+		wantAfter     int64
+	}{
+		{"valeurs par défaut", "", true, apiDefaultLimit, false, 0},
+		{"limite choisie", "?limit=5", true, 5, false, 0},
+		{"limite maximale", "?limit=100", true, apiMaxLimit, false, 0},
+		{"curseur", "?limit=10&cursor=" + encodeCursor(int64(42)), true, 10, true, 42},
+		{"limite nulle", "?limit=0", false, 0, false, 0},
+		{"limite trop grande", "?limit=101", false, 0, false, 0},
+		{"limite non numérique", "?limit=dix", false, 0, false, 0},
+		{"curseur invalide", "?cursor=%21%21", false, 0, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/users"+tt.query, nil)
+
+			var after int64
+			limit, hasCursor, ok := apiPage(w, r, &after)
+			if ok != tt.wantOK {
+				t.Fatalf("apiPage() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if !ok {
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+				}
+				var body struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Error.Code != apiErrBadRequest {
+					t.Errorf("error body = %+v (%v), want code %q", body, err, apiErrBadRequest)
+				}
+				return
+			}
+
+			if limit != tt.wantLimit || hasCursor != tt.wantHasCursor || after != tt.wantAfter {
+				t.Errorf("apiPage() = %d, %v, after %d, want %d, %v, after %d",
+					limit, hasCursor, after, tt.wantLimit, tt.wantHasCursor, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestListPostsCursorValue(t *testing.T) {
+	db := newTestDB(t)
+	h := NewAPIHandler(models.NewPostStore(db), models.NewTagStore(db), models.NewCommentStore(db), models.NewUserStore(db),
+		models.NewReactionStore(db, models.NewReactionRegistry(models.DefaultReactionTypes)), models.NewActivityStore(db), models.NewRevisionStore(db), nil)
+	author := newTestUser(t, db, "alice")
+	newTestPost(t, db, author, models.StatusApproved)
+
+	cursor := func(sort string, value interface{}) string {
+		return encodeCursor(apiPostCursor{Sort: sort, Order: "desc", PostCursor: models.PostCursor{Value: value, ID: 1000}})
+	}
+
+	tests := []struct {
+		name       string
+		sort       string
+		cursor     string
+		wantStatus int
+	}{
+		{"date en jours juliens", "date", cursor("date", 2460000.5), http.StatusOK},
+		{"date en texte", "date", cursor("date", "2025-03-28 09:00:00"), http.StatusBadRequest},
+		{"likes numériques", "likes", cursor("likes", 3), http.StatusOK},
+		{"likes en texte", "likes", cursor("likes", "3"), http.StatusBadRequest},
+		{"commentaires sans valeur", "comments", cursor("comments", nil), http.StatusBadRequest},
+		{"activité en objet", "activity", cursor("activity", map[string]int{"a": 1}), http.StatusBadRequest},
+		{"titre en texte", "title", cursor("title", "Canaux"), http.StatusOK},
+		{"titre numérique", "title", cursor("title", 12), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/posts?sort="+tt.sort+"&cursor="+tt.cursor, nil)
+			h.ListPosts(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusBadRequest {
+				return
+			}
+			var body struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Error.Code != apiErrBadRequest {
+				t.Errorf("error body = %+v (%v), want code %q", body, err, apiErrBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"forum/models"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// apiUserView est la représentation publique d'un utilisateur. L'email et
+// les préférences ne sont renvoyés qu'à l'utilisateur lui-même.
+type apiUserView struct {
+	ID                int64     `json:"id"`
+	Username          string    `json:"username"`
+	AvatarURL         string    `json:"avatar_url"`
+	Role              string    `json:"role"`
+	CreatedAt         time.Time `json:"created_at"`
+	Email             string    `json:"email,omitempty"`
+	AnonymousDislikes *bool     `json:"anonymous_dislikes,omitempty"`
+}
+
+func newAPIUserView(user *models.User, private bool) apiUserView {
+	view := apiUserView{
+		ID:        user.ID,
+		Username:  user.Username,
+		AvatarURL: user.GetAvatarURL(),
+		Role:      user.Role.String(),
+		CreatedAt: user.CreatedAt,
+	}
+	if private {
+		view.Email = user.Email
+		view.AnonymousDislikes = &user.AnonymousDislikes
+	}
+	return view
+}
+
+// apiUserInput est le corps des requêtes d'inscription et de modification
+// du profil. Les champs absents d'une modification restent inchangés.
+type apiUserInput struct {
+	Username          *string `json:"username"`
+	Email             *string `json:"email"`
+	Password          *string `json:"password"` // Inscription uniquement
+	AnonymousDislikes *bool   `json:"anonymous_dislikes"`
+}
+
+// ListUsers liste les utilisateurs par ordre d'inscription
+func (h *APIHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	var afterID int64
+	limit, _, ok := apiPage(w, r, &afterID)
+	if !ok {
+		return
+	}
+
+	users, err := h.UserStore.List(afterID, limit+1)
+	if err != nil {
+		writeAPIInternalError(w, "liste des utilisateurs", err)
+		return
+	}
+
+	next := ""
+	if len(users) > limit {
+		users = users[:limit]
+		next = encodeCursor(users[limit-1].ID)
+	}
+
+	views := make([]apiUserView, len(users))
+	for i, user := range users {
+		views[i] = newAPIUserView(user, false)
+	}
+	writeAPIList(w, views, next)
+}
+
+// GetUser renvoie le profil public d'un utilisateur
+func (h *APIHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.UserStore.GetByID(apiID(r))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "Utilisateur non trouvé")
+		return
+	}
+	writeAPIData(w, http.StatusOK, newAPIUserView(user, false))
+}
+
+// GetMe renvoie le profil de l'utilisateur connecté
+func (h *APIHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+	writeAPIData(w, http.StatusOK, newAPIUserView(user, true))
+}
+
+// CreateUser inscrit un nouvel utilisateur, avec les mêmes règles que le
+// formulaire d'inscription
+func (h *APIHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var input apiUserInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+	if input.Username == nil || input.Email == nil || input.Password == nil ||
+		strings.TrimSpace(*input.Username) == "" || strings.TrimSpace(*input.Email) == "" || *input.Password == "" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le nom d'utilisateur, l'email et le mot de passe sont obligatoires")
+		return
+	}
+
+	user := &models.User{AvatarURL: "/static/assets/pfp_placeholder.jpg"}
+	if !h.applyUserInput(w, user, input) {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		writeAPIInternalError(w, "hachage du mot de passe", err)
+		return
+	}
+	user.Password = string(hashedPassword)
+
+	if user.UUID, err = GenerateUUID(); err != nil {
+		writeAPIInternalError(w, "génération de l'UUID", err)
+		return
+	}
+
+	if err := h.UserStore.Create(user); err != nil {
+		writeAPIInternalError(w, "création de l'utilisateur", err)
+		return
+	}
+	if user.AnonymousDislikes {
+		if err := h.UserStore.SetAnonymousDislikes(user.ID, true); err != nil {
+			log.Printf("Erreur lors de la mise à jour des préférences: %v", err)
+		}
+	}
+
+	created, err := h.UserStore.GetByID(user.ID)
+	if err != nil {
+		writeAPIInternalError(w, "lecture de l'utilisateur", err)
+		return
+	}
+	writeAPIData(w, http.StatusCreated, newAPIUserView(created, true))
+}
+
+// UpdateMe modifie le nom, l'email ou les préférences de l'utilisateur connecté
+func (h *APIHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	current, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input apiUserInput
+	if !decodeAPIBody(w, r, &input) {
+		return
+	}
+	if input.Password != nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le mot de passe ne peut pas être modifié par l'API")
+		return
+	}
+
+	user := *current
+	if !h.applyUserInput(w, &user, input) {
+		return
+	}
+
+	if err := h.UserStore.UpdateProfile(user.ID, user.Username, user.Email); err != nil {
+		writeAPIInternalError(w, "mise à jour du profil", err)
+		return
+	}
+	if err := h.UserStore.SetAnonymousDislikes(user.ID, user.AnonymousDislikes); err != nil {
+		writeAPIInternalError(w, "mise à jour des préférences", err)
+		return
+	}
+
+	updated, err := h.UserStore.GetByID(user.ID)
+	if err != nil {
+		writeAPIInternalError(w, "lecture de l'utilisateur", err)
+		return
+	}
+	writeAPIData(w, http.StatusOK, newAPIUserView(updated, true))
+}
+
+// applyUserInput reporte les champs renseignés sur l'utilisateur ; un nom ou
+// un email déjà utilisé par un autre compte est refusé
+func (h *APIHandler) applyUserInput(w http.ResponseWriter, user *models.User, input apiUserInput) bool {
+	if input.Username != nil {
+		username := strings.TrimSpace(*input.Username)
+		if username == "" {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "Le nom d'utilisateur est obligatoire")
+			return false
+		}
+		if existing, err := h.UserStore.GetByUsername(username); err == nil && existing.ID != user.ID {
+			writeAPIError(w, http.StatusConflict, apiErrConflict, "Ce nom d'utilisateur est déjà pris")
+			return false
+		}
+		user.Username = username
+	}
+	if input.Email != nil {
+		email := strings.TrimSpace(*input.Email)
+		if email == "" {
+			writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "L'email est obligatoire")
+			return false
+		}
+		if existing, err := h.UserStore.GetByEmail(email); err == nil && existing.ID != user.ID {
+			writeAPIError(w, http.StatusConflict, apiErrConflict, "Cet email est déjà enregistré")
+			return false
+		}
+		user.Email = email
+	}
+	if input.AnonymousDislikes != nil {
+		user.AnonymousDislikes = *input.AnonymousDislikes
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"forum/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const apiTokenContextKey contextKey = "api_token"
+
+// Longueur maximale du nom d'un jeton d'API
+const apiTokenNameMaxLength = 100
+
+// APITokenMiddleware authentifie les requêtes de l'API portant un en-tête
+// Authorization: Bearer <jeton>. Le jeton remplace alors le cookie de session
+// pour la requête. Il n'est accepté que sous /api/ : un jeton ne donne pas
+// accès aux pages du site, ni à la gestion des jetons.
+func APITokenMiddleware(tokenStore *models.APITokenStore, userStore *models.UserStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, plain, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !strings.HasPrefix(r.URL.Path, "/api/") {
+				http.Error(w, "Les jetons d'API ne sont acceptés que sur /api/", http.StatusUnauthorized)
+				return
+			}
+
+			token, err := tokenStore.GetByToken(strings.TrimSpace(plain))
+			if err != nil {
+				writeAPIError(w, http.StatusUnauthorized, apiErrUnauthorized, "Jeton d'API invalide ou révoqué")
+				return
+			}
+
+			user, err := userStore.GetByID(token.UserID)
+			if err != nil {
+				log.Printf("Jeton d'API %d lié à un utilisateur introuvable: %v", token.ID, err)
+				writeAPIError(w, http.StatusUnauthorized, apiErrUnauthorized, "Jeton d'API invalide ou révoqué")
+				return
+			}
+			if user.IsBanned() {
+				writeAPIError(w, http.StatusForbidden, apiErrForbidden, "Ce compte a été suspendu")
+				return
+			}
+
+			if !token.Scope.Allows(r.Method) {
+				writeAPIError(w, http.StatusForbidden, apiErrForbidden, "Ce jeton ne permet que la lecture")
+				return
+			}
+
+			if err := tokenStore.Touch(token); err != nil {
+				log.Printf("Erreur lors de la mise à jour du jeton d'API %d: %v", token.ID, err)
+			}
+
+			// La session éventuelle du cookie est ignorée au profit du jeton
+			ctx := context.WithValue(r.Context(), userContextKey, user)
+			ctx = context.WithValue(ctx, sessionContextKey, (*models.Session)(nil))
+			ctx = context.WithValue(ctx, apiTokenContextKey, token)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetCurrentAPIToken retourne le jeton d'API ayant authentifié la requête, ou nil
+func GetCurrentAPIToken(r *http.Request) *models.APIToken {
+	token, _ := r.Context().Value(apiTokenContextKey).(*models.APIToken)
+	return token
+}
+
+// CreateAPIToken crée un jeton d'API et affiche sa valeur, une seule fois
+func (h *ProfileHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromRequest(r)
+	if userID == 0 {
+		http.Error(w, "Non autorisé", http.StatusUnauthorized)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" || utf8.RuneCountInString(name) > apiTokenNameMaxLength {
+		http.Error(w, "Le nom du jeton est obligatoire ("+strconv.Itoa(apiTokenNameMaxLength)+" caractères au plus)", http.StatusBadRequest)
+		return
+	}
+
+	scope, ok := models.ParseTokenScope(r.FormValue("scope"))
+	if !ok {
+		http.Error(w, "Portée de jeton invalide", http.StatusBadRequest)
+		return
+	}
+
+	token, plain, err := h.APITokenStore.Create(userID, name, scope)
+	if err != nil {
+		log.Printf("Erreur lors de la création du jeton d'API: %v", err)
+		http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Jeton d'API %d créé pour l'utilisateur %d", token.ID, userID)
+
+	// Le jeton en clair n'est ni conservé ni mis en cache
+	w.Header().Set("Cache-Control", "no-store")
+	h.renderProfile(w, r, map[string]interface{}{
+		"NewAPIToken":      plain,
+		"NewAPITokenName":  token.Name,
+		"NewAPITokenScope": token.Scope,
+	})
+}
+
+// RevokeAPIToken révoque un jeton d'API de l'utilisateur connecté
+func (h *ProfileHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromRequest(r)
+	if userID == 0 {
+		http.Error(w, "Non autorisé", http.StatusUnauthorized)
+		return
+	}
+
+	tokenID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de jeton invalide", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.APITokenStore.DeleteByID(tokenID, userID); err != nil {
+		log.Printf("Erreur lors de la révocation du jeton d'API %d: %v", tokenID, err)
+		http.Error(w, "Jeton non trouvé", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("Jeton d'API %d révoqué pour l'utilisateur %d", tokenID, userID)
+	http.Redirect(w, r, "/profile#api-tokens", http.StatusSeeOther)
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"crypto/rand"
-	"database/sql"
 	"fmt"
 	"forum/models"
 	"io"
@@ -10,54 +9,19 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gorilla/mux"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
-	UserStore *models.UserStore
+	UserStore    *models.UserStore
+	SessionStore *models.SessionStore
 }
 
-// installation de la base de donnée
-var db *sql.DB
-
-func SetDB(database *sql.DB) {
-	db = database
-}
-
-func RegisterAuthRoutes(r *mux.Router) {
-	h := NewAuthHandler(models.NewUserStore(db))
-
-	// Routes d'authentification
-	r.HandleFunc("/login", h.ShowLogin).Methods("GET")
-	r.HandleFunc("/login", h.Login).Methods("POST")
-	r.HandleFunc("/register", h.Register).Methods("POST")
-
-	// Routes protégée
-	r.HandleFunc("/logout", h.requireAuth(h.Logout)).Methods("GET")
-}
-
-// wrapper pour les handlers protégés
-func (h *AuthHandler) requireAuth(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if !h.isAuthenticated(r) {
-			http.SetCookie(w, &http.Cookie{
-				Name:  "redirect",
-				Value: r.URL.Path,
-				Path:  "/",
-			})
-			http.Redirect(w, r, "/login", http.StatusSeeOther)
-			return
-		}
-		next(w, r)
-	}
-}
-func NewAuthHandler(userStore *models.UserStore) *AuthHandler {
-	return &AuthHandler{UserStore: userStore}
+func NewAuthHandler(userStore *models.UserStore, sessionStore *models.SessionStore) *AuthHandler {
+	return &AuthHandler{UserStore: userStore, SessionStore: sessionStore}
 }
 
 func (h *AuthHandler) ShowLogin(w http.ResponseWriter, r *http.Request) {
@@ -75,7 +39,7 @@ func (h *AuthHandler) ShowLogin(w http.ResponseWriter, r *http.Request) {
 		data["PageTitle"] = "Inscription"
 	}
 
-	RenderTemplate(w, "auth.html", data)
+	RenderTemplate(w, r, "auth.html", data)
 }
 
 // s'inscrire
@@ -115,7 +79,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[Register] Champs manquants - Username: %t, Email: %t, Password: %t, ConfirmPassword: %t",
 			username == "", email == "", password == "", confirmPassword == "")
 
-		RenderTemplate(w, "auth.html", map[string]interface{}{
+		RenderTemplate(w, r, "auth.html", map[string]interface{}{
 			"Action": "register",
 			"Error":  "Tous les champs sont obligatoires",
 			"FormData": map[string]string{
@@ -127,7 +91,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if password != confirmPassword {
-		RenderTemplate(w, "auth.html", map[string]interface{}{
+		RenderTemplate(w, r, "auth.html", map[string]interface{}{
 			"Action": "register",
 			"Error":  "Les mots de passe ne correspondent pas",
 			"FormData": map[string]string{
@@ -140,7 +104,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	//est ce que l'username est unique
 	if _, err := h.UserStore.GetByUsername(username); err == nil {
-		RenderTemplate(w, "auth.html", map[string]interface{}{
+		RenderTemplate(w, r, "auth.html", map[string]interface{}{
 			"Action": "register",
 			"Error":  "Ce nom d'utilisateur est déjà pris",
 			"FormData": map[string]string{
@@ -152,7 +116,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if _, err := h.UserStore.GetByEmail(email); err == nil {
-		RenderTemplate(w, "auth.html", map[string]interface{}{
+		RenderTemplate(w, r, "auth.html", map[string]interface{}{
 			"Action": "register",
 			"Error":  "Cet email est déjà enregistré",
 			"FormData": map[string]string{
@@ -167,19 +131,18 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		log.Printf("[Register] Erreur hachage mot de passe: %v", err)
-		RenderTemplate(w, "auth.html", map[string]interface{}{
+		RenderTemplate(w, r, "auth.html", map[string]interface{}{
 			"Action": "register",
 			"Error":  "Erreur serveur - veuillez réessayer",
 		})
 		return
 	}
-	log.Printf("[Register] Hash généré pour nouvel utilisateur: %s", string(hashedPassword))
 
 	// Génération UUID
 	uuid, err := GenerateUUID()
 	if err != nil {
 		log.Printf("[Register] Erreur génération UUID: %v", err)
-		RenderTemplate(w, "auth.html", map[string]interface{}{
+		RenderTemplate(w, r, "auth.html", map[string]interface{}{
 			"Action": "register",
 			"Error":  "Erreur serveur - veuillez réessayer",
 		})
@@ -199,7 +162,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.UserStore.Create(user); err != nil {
 		log.Printf("[Register] Erreur création user: %v", err)
-		RenderTemplate(w, "auth.html", map[string]interface{}{
+		RenderTemplate(w, r, "auth.html", map[string]interface{}{
 			"Action": "register",
 			"Error":  "Impossible de créer le compte utilisateur",
 		})
@@ -238,16 +201,12 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// On utilise directement l'ID retourné par Create()
-	log.Printf("[Register] Création du cookie avec user_id=%d", user.ID)
-	http.SetCookie(w, &http.Cookie{
-		Name:     "user_id",
-		Value:    strconv.FormatInt(user.ID, 10),
-		Expires:  time.Now().Add(24 * time.Hour),
-		HttpOnly: true,
-		Path:     "/",
-		Secure:   r.TLS != nil,
-		SameSite: http.SameSiteLaxMode,
-	})
+	log.Printf("[Register] Ouverture de la session pour user_id=%d", user.ID)
+	if err := startSession(w, r, h.SessionStore, user.ID); err != nil {
+		log.Printf("[Register] Erreur création session: %v", err)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
 
 	//log pour confirmer la redirection
 	w.Header().Set("Cache-Control", "no-store")
@@ -255,19 +214,6 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 	log.Println("[Register] Après redirection")
 }
-func getUserIDFromCookie(r *http.Request) int64 {
-	cookie, err := r.Cookie("user_id")
-	if err != nil {
-		return 0
-	}
-
-	userID, err := strconv.ParseInt(cookie.Value, 10, 64)
-	if err != nil {
-		return 0
-	}
-
-	return userID
-}
 
 // Login handles user authentication
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -290,7 +236,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	email := r.FormValue("email")
 	password := r.FormValue("password")
-	log.Printf("Données reçues - Email: [%s], Password: [%s]\n", email, password)
+	log.Printf("Tentative de connexion - Email: [%s]\n", email)
 
 	// Validate input
 	if email == "" || password == "" {
@@ -304,13 +250,11 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	user, err := h.UserStore.GetByEmail(email)
 	if err != nil {
 		log.Printf("ERREUR - Utilisateur non trouvé: %v\n", err)
-		log.Printf("Détails DB: %+v\n", h.UserStore)
 		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
 	log.Printf("Utilisateur trouvé: ID=%d, Email=%s\n", user.ID, user.Email)
-	log.Printf("Hash stocké dans DB: %s\n", user.Password)
 
 	// verif mdp
 	log.Println("Comparaison du mot de passe avec le hash...")
@@ -326,63 +270,34 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("Authentification réussie, création du cookie...")
-	http.SetCookie(w, &http.Cookie{
-		Name:     "user_id",
-		Value:    strconv.FormatInt(user.ID, 10),
-		Expires:  time.Now().Add(24 * time.Hour),
-		HttpOnly: true,
-		Path:     "/",
-		Secure:   r.TLS != nil,
-		SameSite: http.SameSiteLaxMode,
-	})
+	if user.IsBanned() {
+		log.Printf("Connexion refusée: utilisateur %d suspendu\n", user.ID)
+		http.Error(w, "Ce compte a été suspendu", http.StatusForbidden)
+		return
+	}
+
+	log.Println("Authentification réussie, création de la session...")
+	if err := startSession(w, r, h.SessionStore, user.ID); err != nil {
+		log.Printf("ERREUR - Création de session impossible: %v\n", err)
+		http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+		return
+	}
 
 	log.Println("Redirection vers la page d'accueil")
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:   "user_id",
-		Value:  "",
-		Path:   "/",
-		MaxAge: -1,
-	})
+	// Révocation de la session côté serveur
+	if cookie, err := r.Cookie(sessionCookieName); err == nil && cookie.Value != "" {
+		if err := h.SessionStore.DeleteByToken(cookie.Value); err != nil {
+			log.Printf("Erreur lors de la révocation de la session: %v", err)
+		}
+	}
+	clearSessionCookie(w)
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-func (h *AuthHandler) isAuthenticated(r *http.Request) bool {
-	cookie, err := r.Cookie("user_id")
-	if err != nil {
-		return false
-	}
-
-	userID, err := strconv.ParseInt(cookie.Value, 10, 64)
-	if err != nil || userID <= 0 {
-		return false
-	}
-
-	// est ce que l'user existe
-	_, err = h.UserStore.GetByID(userID)
-	return err == nil
-}
-func (h *AuthHandler) authMiddleware() mux.MiddlewareFunc {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !h.isAuthenticated(r) {
-				// Stocke l'URL demandée pour redirection après login
-				http.SetCookie(w, &http.Cookie{
-					Name:  "redirect",
-					Value: r.URL.Path,
-					Path:  "/",
-				})
-				http.Redirect(w, r, "/login", http.StatusSeeOther)
-				return
-			}
-			next.ServeHTTP(w, r)
-		})
-	}
-}
 func GenerateUUID() (string, error) {
 	uuid := make([]byte, 16)
 	_, err := rand.Read(uuid)
@@ -396,16 +311,3 @@ func GenerateUUID() (string, error) {
 	return fmt.Sprintf("%x-%x-%x-%x-%x",
 		uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
 }
-func GetUserIDFromRequest(r *http.Request) int64 {
-	cookie, err := r.Cookie("user_id")
-	if err != nil {
-		return 0
-	}
-
-	userID, err := strconv.ParseInt(cookie.Value, 10, 64)
-	if err != nil {
-		return 0
-	}
-
-	return userID
-}
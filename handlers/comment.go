@@ -12,12 +12,20 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func RegisterCommentRoutes(r *mux.Router) {
+func RegisterCommentRoutes(r *mux.Router, moderation *models.ModerationPolicy) {
 	// r.HandleFunc("/comments", CreateCommentHandler).Methods("POST")
-	r.HandleFunc("/post/{id}/comment", PostCommentHandler).Methods("POST")
+	r.HandleFunc("/post/{id}/comment", func(w http.ResponseWriter, r *http.Request) {
+		PostCommentHandler(w, r, moderation)
+	}).Methods("POST")
+	r.HandleFunc("/comment/{id:[0-9]+}/edit", func(w http.ResponseWriter, r *http.Request) {
+		EditCommentHandler(w, r, moderation)
+	}).Methods("POST")
+	r.HandleFunc("/comment/{id:[0-9]+}/delete", DeleteCommentHandler).Methods("POST")
 }
-func PostCommentHandler(w http.ResponseWriter, r *http.Request) {
-	userID := getUserIDFromCookie(r)
+
+// PostCommentHandler crée un commentaire, soumis à validation selon la politique de pré-modération
+func PostCommentHandler(w http.ResponseWriter, r *http.Request, moderation *models.ModerationPolicy) {
+	userID := GetUserIDFromRequest(r)
 	if userID == 0 {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
@@ -32,12 +40,35 @@ func PostCommentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Seuls les posts visibles par l'utilisateur et hors corbeille acceptent des commentaires
+	postStore := models.NewPostStore(database.GetDB())
+	post, err := postStore.GetByID(postID)
+	if err != nil || post.Status == models.PostStatusDeleted || (!post.Status.IsPublic() && !canEditPost(r, post)) {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
 	content := r.FormValue("content")
 	if content == "" {
 		http.Error(w, "Comment content is required", http.StatusBadRequest)
 		return
 	}
 
+	// Réponse à un commentaire existant du même post
+	var parent *models.Comment
+	if parentIDStr := r.FormValue("parent_id"); parentIDStr != "" {
+		parentID, err := strconv.ParseInt(parentIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid parent comment ID", http.StatusBadRequest)
+			return
+		}
+		parent, err = models.NewCommentStore(database.GetDB()).GetByID(parentID)
+		if err != nil || parent.PostID != postID || parent.Status == models.PostStatusDeleted {
+			http.Error(w, "Parent comment not found", http.StatusNotFound)
+			return
+		}
+	}
+
 	// Initialiser tous les champs nécessaires
 	now := time.Now()
 	comment := &models.Comment{
@@ -48,6 +79,10 @@ func PostCommentHandler(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt:    now,
 		LikeCount:    0,
 		DislikeCount: 0,
+		Status:       moderation.InitialStatus(GetCurrentUser(r)),
+	}
+	if parent != nil {
+		comment.ParentID = parent.ID
 	}
 
 	log.Printf("Tentative de création d'un commentaire: PostID=%d, UserID=%d", postID, userID)
@@ -60,30 +95,12 @@ func PostCommentHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Commentaire créé avec succès, ID=%d", comment.ID)
 
-	// Récupérer les informations du post pour la notification
-	postStore := models.NewPostStore(database.GetDB())
-	post, err := postStore.GetByID(postID)
-	if err != nil {
-		log.Printf("Erreur lors de la récupération du post pour la notification: %v", err)
-	} else if post.UserID != userID { // Ne pas notifier si l'utilisateur commente son propre post
-		// Créer une notification pour le propriétaire du post
-		activityStore := models.NewActivityStore(database.GetDB())
-
-		activity := &models.Activity{
-			UserID:      userID,      // L'auteur du commentaire
-			RecipientID: post.UserID, // Le propriétaire du post
-			Type:        models.ActivityComment,
-			TargetID:    postID,
-			CreatedAt:   now,
-			Content:     "a commenté sur votre post",
-			IsRead:      false,
-		}
-
-		if err := activityStore.Create(activity); err != nil {
-			log.Printf("Failed to create notification: %v", err)
-		} else {
-			log.Printf("Notification créée avec succès pour l'utilisateur %d", post.UserID)
-		}
+	activityStore := models.NewActivityStore(database.GetDB())
+	if comment.Status == models.StatusPending {
+		// Les notifications seront envoyées lors de la validation du commentaire
+		log.Printf("Commentaire %d en attente de validation", comment.ID)
+	} else {
+		notifyNewComment(activityStore, post, parent, comment)
 	}
 
 	// ajouter a l'activité (pour l'historique)
@@ -97,9 +114,147 @@ func PostCommentHandler(w http.ResponseWriter, r *http.Request) {
 		IsRead:      true, // Déjà lue puisque c'est l'utilisateur qui l'a créée
 	}
 
-	if err := models.NewActivityStore(database.GetDB()).Create(activity); err != nil {
+	if err := activityStore.Create(activity); err != nil {
 		log.Printf("Failed to create activity record: %v", err)
 	}
 
-	http.Redirect(w, r, fmt.Sprintf("/post/%d", postID), http.StatusSeeOther)
+	http.Redirect(w, r, fmt.Sprintf("/post/%d#comment-%d", postID, comment.ID), http.StatusSeeOther)
+}
+
+// EditCommentHandler modifie le contenu d'un commentaire, par son auteur ou un
+// modérateur ; la modification repasse par la pré-modération
+func EditCommentHandler(w http.ResponseWriter, r *http.Request, moderation *models.ModerationPolicy) {
+	commentStore := models.NewCommentStore(database.GetDB())
+	comment, ok := editableComment(w, r, commentStore, models.NewPostStore(database.GetDB()))
+	if !ok {
+		return
+	}
+
+	content := r.FormValue("content")
+	if content == "" {
+		http.Error(w, "Comment content is required", http.StatusBadRequest)
+		return
+	}
+
+	if content != comment.Content {
+		comment.Content = content
+		comment.Status = moderation.EditStatus(GetCurrentUser(r), comment.Status)
+	}
+	if err := commentStore.Update(comment); err != nil {
+		log.Printf("Erreur lors de la modification du commentaire %d: %v", comment.ID, err)
+		http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/post/%d#comment-%d", comment.PostID, comment.ID), http.StatusSeeOther)
+}
+
+// DeleteCommentHandler supprime un commentaire en conservant sa place dans le fil :
+// il est affiché comme « [commentaire supprimé] » et ses réponses restent visibles
+func DeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	commentStore := models.NewCommentStore(database.GetDB())
+	postStore := models.NewPostStore(database.GetDB())
+	comment, ok := editableComment(w, r, commentStore, postStore)
+	if !ok {
+		return
+	}
+
+	if err := deleteComment(commentStore, postStore, comment); err != nil {
+		log.Printf("Erreur lors de la suppression du commentaire %d: %v", comment.ID, err)
+		http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/post/%d#comment-%d", comment.PostID, comment.ID), http.StatusSeeOther)
+}
+
+// deleteComment marque un commentaire comme supprimé et lui retire le statut
+// de réponse acceptée
+func deleteComment(commentStore *models.CommentStore, postStore *models.PostStore, comment *models.Comment) error {
+	if err := commentStore.UpdateStatus(comment.ID, models.PostStatusDeleted); err != nil {
+		return err
+	}
+
+	// Une réponse supprimée ne peut plus être la réponse acceptée
+	if post, err := postStore.GetByID(comment.PostID); err == nil && post.AcceptedCommentID == comment.ID {
+		if err := postStore.SetAcceptedAnswer(post.ID, 0); err != nil {
+			log.Printf("Erreur lors du retrait de la réponse acceptée du post %d: %v", post.ID, err)
+		}
+	}
+	return nil
+}
+
+// editableComment charge le commentaire visé et vérifie que l'utilisateur peut le modifier.
+// Le post parent doit lui rester visible et ne pas être à la corbeille.
+func editableComment(w http.ResponseWriter, r *http.Request, commentStore *models.CommentStore, postStore *models.PostStore) (*models.Comment, bool) {
+	user := GetCurrentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, false
+	}
+
+	commentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	comment, err := commentStore.GetByID(commentID)
+	if err != nil || comment.Status == models.PostStatusDeleted {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	post, err := postStore.GetByID(comment.PostID)
+	if err != nil || post.Status == models.PostStatusDeleted || (!post.Status.IsPublic() && !canEditPost(r, post)) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	if !comment.CanEdit(user.ID, user.Role) {
+		http.Error(w, "Vous n'êtes pas autorisé à modifier ce commentaire", http.StatusForbidden)
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// notifyNewComment prévient l'auteur du commentaire parent d'une réponse,
+// et le propriétaire du post d'un nouveau commentaire
+func notifyNewComment(activityStore *models.ActivityStore, post *models.Post, parent *models.Comment, comment *models.Comment) {
+	if parent != nil && parent.UserID != comment.UserID {
+		activity := &models.Activity{
+			UserID:      comment.UserID,
+			RecipientID: parent.UserID,
+			Type:        models.ActivityReply,
+			TargetID:    post.ID,
+			CreatedAt:   time.Now(),
+			Content:     "a répondu à votre commentaire",
+			IsRead:      false,
+		}
+		if err := activityStore.Create(activity); err != nil {
+			log.Printf("Failed to create reply notification: %v", err)
+		}
+	}
+
+	// Ne pas notifier si l'utilisateur commente son propre post,
+	// ni deux fois le propriétaire du post s'il est l'auteur du commentaire parent
+	if post.UserID == comment.UserID || (parent != nil && parent.UserID == post.UserID) {
+		return
+	}
+
+	activity := &models.Activity{
+		UserID:      comment.UserID, // L'auteur du commentaire
+		RecipientID: post.UserID,    // Le propriétaire du post
+		Type:        models.ActivityComment,
+		TargetID:    post.ID,
+		CreatedAt:   time.Now(),
+		Content:     "a commenté sur votre post",
+		IsRead:      false,
+	}
+	if err := activityStore.Create(activity); err != nil {
+		log.Printf("Failed to create notification: %v", err)
+	} else {
+		log.Printf("Notification créée avec succès pour l'utilisateur %d", post.UserID)
+	}
 }
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"forum/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestEditableCommentParentPost(t *testing.T) {
+	db := newTestDB(t)
+	commentStore := models.NewCommentStore(db)
+	postStore := models.NewPostStore(db)
+	author := newTestUser(t, db, "alice")
+	commenter := newTestUser(t, db, "bob")
+	moderator := newTestUser(t, db, "carole")
+	moderator.Role = models.RoleModerator
+
+	tests := []struct {
+		name       string
+		postStatus models.PostStatus
+		user       *models.User
+		wantStatus int
+	}{
+		{"post public", models.StatusApproved, commenter, http.StatusOK},
+		{"post en attente", models.StatusPending, commenter, http.StatusNotFound},
+		{"post masqué", models.PostStatusHidden, commenter, http.StatusNotFound},
+		{"post masqué, par un modérateur", models.PostStatusHidden, moderator, http.StatusOK},
+		{"post à la corbeille", models.PostStatusDeleted, commenter, http.StatusNotFound},
+		{"post à la corbeille, par un modérateur", models.PostStatusDeleted, moderator, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(t, db, author, tt.postStatus)
+			comment := newTestComment(t, db, post, commenter, models.StatusApproved)
+			id := strconv.FormatInt(comment.ID, 10)
+
+			w := httptest.NewRecorder()
+			r := newTestRequest(http.MethodPost, "/comment/"+id+"/edit", tt.user, map[string]string{"id": id})
+			_, ok := editableComment(w, r, commentStore, postStore)
+
+			if ok != (tt.wantStatus == http.StatusOK) || w.Code != tt.wantStatus {
+				t.Errorf("editableComment() = %v with status %d, want status %d", ok, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAPIEditableCommentParentPost(t *testing.T) {
+	db := newTestDB(t)
+	h := &APIHandler{CommentStore: models.NewCommentStore(db), PostStore: models.NewPostStore(db)}
+	author := newTestUser(t, db, "alice")
+	commenter := newTestUser(t, db, "bob")
+	moderator := newTestUser(t, db, "carole")
+	moderator.Role = models.RoleModerator
+
+	tests := []struct {
+		name       string
+		postStatus models.PostStatus
+		user       *models.User
+		wantStatus int
+	}{
+		{"post public", models.StatusApproved, author, http.StatusOK},
+		{"post public, par un autre membre", models.StatusApproved, commenter, http.StatusForbidden},
+		{"post masqué, par un autre membre", models.PostStatusHidden, commenter, http.StatusNotFound},
+		{"post à la corbeille, par son auteur", models.PostStatusDeleted, author, http.StatusConflict},
+		{"post à la corbeille, par un modérateur", models.PostStatusDeleted, moderator, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(t, db, author, tt.postStatus)
+			comment := newTestComment(t, db, post, author, models.StatusApproved)
+			id := strconv.FormatInt(comment.ID, 10)
+
+			w := httptest.NewRecorder()
+			r := newTestRequest(http.MethodPatch, "/api/v1/comments/"+id, tt.user, map[string]string{"id": id})
+			_, ok := h.editableComment(w, r)
+
+			if ok != (tt.wantStatus == http.StatusOK) || w.Code != tt.wantStatus {
+				t.Errorf("editableComment() = %v with status %d, want status %d", ok, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Noms utilisés pour transporter le jeton CSRF
+const (
+	csrfCookieName = "csrf_token"
+	csrfFormField  = "csrf_token"
+	csrfHeaderName = "X-CSRF-Token"
+)
+
+const csrfContextKey contextKey = "csrf_token"
+
+// maxFormSize borne le corps lu pour trouver le jeton dans un formulaire.
+// Il couvre les envois d'images ; les requêtes qui passent le jeton dans
+// l'en-tête gardent leur corps intact et leurs propres limites.
+const maxFormSize = 20 << 20
+
+// CSRFMiddleware protège toutes les requêtes qui modifient l'état.
+// Le jeton est stocké dans un cookie et doit être renvoyé soit dans le
+// champ de formulaire csrf_token, soit dans l'en-tête X-CSRF-Token.
+// Les requêtes authentifiées par un jeton d'API en sont exemptées : le
+// navigateur n'envoie jamais l'en-tête Authorization de lui-même.
+func CSRFMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetCurrentAPIToken(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := ""
+		if cookie, err := r.Cookie(csrfCookieName); err == nil && cookie.Value != "" {
+			token = cookie.Value
+		} else {
+			newToken, err := generateCSRFToken()
+			if err != nil {
+				log.Printf("Erreur lors de la génération du jeton CSRF: %v", err)
+				http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+				return
+			}
+			token = newToken
+			http.SetCookie(w, &http.Cookie{
+				Name:     csrfCookieName,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			sent := r.Header.Get(csrfHeaderName)
+			if sent == "" {
+				r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+				err := r.ParseForm()
+				if err == nil {
+					err = r.ParseMultipartForm(10 << 20)
+				}
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, "Contenu trop volumineux", http.StatusRequestEntityTooLarge)
+					return
+				}
+				sent = r.FormValue(csrfFormField)
+			}
+			if sent == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				log.Printf("Requête %s %s rejetée: jeton CSRF invalide", r.Method, r.URL.Path)
+				http.Error(w, "Jeton CSRF invalide", http.StatusForbidden)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), csrfContextKey, token)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetCSRFToken retourne le jeton CSRF associé à la requête
+func GetCSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfContextKey).(string)
+	return token
+}
+
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"forum/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	const token = "jeton-de-test"
+
+	tests := []struct {
+		name       string
+		method     string
+		cookie     string
+		header     string
+		form       string
+		apiToken   bool
+		wantStatus int
+	}{
+		{"lecture sans jeton", http.MethodGet, "", "", "", false, http.StatusOK},
+		{"écriture sans jeton", http.MethodPost, token, "", "", false, http.StatusForbidden},
+		{"écriture sans cookie", http.MethodPost, "", "", token, false, http.StatusForbidden},
+		{"jeton dans le formulaire", http.MethodPost, token, "", token, false, http.StatusOK},
+		{"jeton dans l'en-tête", http.MethodDelete, token, token, "", false, http.StatusOK},
+		{"mauvais jeton", http.MethodPost, token, "", "autre-jeton", false, http.StatusForbidden},
+		{"jeton d'API", http.MethodPost, "", "", "", true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := CSRFMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = GetCSRFToken(r)
+			}))
+
+			var body *strings.Reader
+			if tt.form != "" {
+				body = strings.NewReader(url.Values{csrfFormField: {tt.form}}.Encode())
+			} else {
+				body = strings.NewReader("")
+			}
+			r := httptest.NewRequest(tt.method, "/post/1/comment", body)
+			if tt.form != "" {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				r.Header.Set(csrfHeaderName, tt.header)
+			}
+			if tt.apiToken {
+				r = r.WithContext(context.WithValue(r.Context(), apiTokenContextKey, &models.APIToken{ID: 1}))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && !tt.apiToken && seen == "" {
+				t.Error("CSRF token missing from the request context")
+			}
+		})
+	}
+}
+
+func TestCSRFMiddlewareSetsCookie(t *testing.T) {
+	handler := CSRFMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(GetCSRFToken(r)))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != csrfCookieName {
+		t.Fatalf("cookies = %v, want a single %s cookie", cookies, csrfCookieName)
+	}
+	if !cookies[0].HttpOnly || cookies[0].Value == "" || cookies[0].Value != w.Body.String() {
+		t.Errorf("cookie %+v does not match the token %q given to the templates", cookies[0], w.Body.String())
+	}
+}
+
+func TestCSRFMiddlewareBodyLimits(t *testing.T) {
+	const token = "jeton-de-test"
+	user := &models.User{ID: 1, Username: "alice"}
+
+	tests := []struct {
+		name       string
+		header     bool
+		size       int
+		wantStatus int
+	}{
+		// Le jeton dans l'en-tête laisse l'aperçu appliquer sa propre limite
+		{"aperçu avec l'en-tête", true, maxPreviewSize + 1, http.StatusRequestEntityTooLarge},
+		{"aperçu raisonnable", true, 1 << 10, http.StatusOK},
+		{"formulaire trop volumineux", false, maxFormSize + 1, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := CSRFMiddleware(http.HandlerFunc(PreviewMarkdown))
+
+			form := url.Values{csrfFormField: {token}, "content": {strings.Repeat("a", tt.size)}}
+			r := httptest.NewRequest(http.MethodPost, "/preview", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+			if tt.header {
+				r.Header.Set(csrfHeaderName, token)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Style de coloration ; la feuille CSS correspondante est static/css/highlight.css
+const highlightStyle = "github"
+
+// Nombre maximal de posts rendus gardés en mémoire
+const maxRenderCacheEntries = 500
+
+var codeFormatter = chromahtml.New(
+	chromahtml.WithClasses(true),
+	chromahtml.WithLineNumbers(true),
+	chromahtml.LineNumbersInTable(true),
+)
+
+// codeBlocks collecte les blocs de code d'un rendu Markdown. Le rendu Markdown n'émet
+// qu'un marqueur à leur place : le HTML coloré est inséré après l'assainissement,
+// qui supprimerait sinon les classes de coloration.
+type codeBlocks struct {
+	nonce  string
+	blocks []string
+}
+
+func newCodeBlocks() *codeBlocks {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return &codeBlocks{nonce: hex.EncodeToString(buf)}
+}
+
+func (c *codeBlocks) marker(i int) string {
+	return fmt.Sprintf("codeblock-%s-%d", c.nonce, i)
+}
+
+// renderHook remplace chaque bloc de code par un marqueur et le colore
+func (c *codeBlocks) renderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	block, ok := node.(*ast.CodeBlock)
+	if !ok {
+		return ast.GoToNext, false
+	}
+
+	language := strings.Fields(string(block.Info))
+	lang := ""
+	if len(language) > 0 {
+		lang = language[0]
+	}
+
+	io.WriteString(w, "\n"+c.marker(len(c.blocks))+"\n")
+	c.blocks = append(c.blocks, highlightCode(string(block.Literal), lang))
+	return ast.GoToNext, true
+}
+
+// restore remet le HTML coloré à la place des marqueurs
+func (c *codeBlocks) restore(html string) string {
+	for i, block := range c.blocks {
+		html = strings.Replace(html, c.marker(i), block, 1)
+	}
+	return html
+}
+
+// highlightCode colore un bloc de code et l'entoure du bouton de copie
+func highlightCode(code, lang string) string {
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	var out bytes.Buffer
+	out.WriteString(`<div class="code-block">`)
+	if lang != "" {
+		out.WriteString(`<span class="code-language">` + template.HTMLEscapeString(lang) + `</span>`)
+	}
+	out.WriteString(`<button type="button" class="copy-code-btn">Copier</button>`)
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err == nil {
+		err = codeFormatter.Format(&out, styles.Get(highlightStyle), iterator)
+	}
+	if err != nil {
+		// En cas d'échec, le code est affiché sans coloration
+		out.WriteString(`<pre class="chroma"><code>` + template.HTMLEscapeString(code) + `</code></pre>`)
+	}
+
+	out.WriteString(`</div>`)
+	return out.String()
+}
+
+// renderCache garde le rendu HTML des posts, indexé par post et révision
+type renderCache struct {
+	mu      sync.RWMutex
+	entries map[string]template.HTML
+}
+
+var postRenderCache = &renderCache{entries: make(map[string]template.HTML)}
+
+func (c *renderCache) get(key string) (template.HTML, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	html, ok := c.entries[key]
+	return html, ok
+}
+
+func (c *renderCache) set(key string, html template.HTML) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Cache volontairement simple : on repart de zéro une fois plein
+	if len(c.entries) >= maxRenderCacheEntries {
+		c.entries = make(map[string]template.HTML)
+	}
+	c.entries[key] = html
+}
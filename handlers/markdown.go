@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"forum/models"
+
+	"github.com/gomarkdown/markdown"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Taille maximale d'un contenu envoyé pour l'aperçu
+const maxPreviewSize = 64 << 10
+
+// Extensions Markdown reconnues : blocs de code, tableaux, listes, liens automatiques, barré
+const markdownExtensions = parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
+
+// RenderMarkdown convertit un contenu Markdown en HTML assaini, prêt à être affiché
+func RenderMarkdown(source string) template.HTML {
+	// Le parser conserve un état interne : une instance par rendu
+	p := parser.NewWithExtensions(markdownExtensions)
+	code := newCodeBlocks()
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{
+		Flags:          mdhtml.CommonFlags | mdhtml.Safelink,
+		RenderNodeHook: code.renderHook,
+	})
+
+	output := markdown.ToHTML(markdown.NormalizeNewlines([]byte(source)), p, renderer)
+	return template.HTML(code.restore(SanitizeHTML(string(output))))
+}
+
+// RenderPost retourne le rendu du contenu d'un post, mis en cache pour chaque révision
+func RenderPost(post *models.Post) template.HTML {
+	key := fmt.Sprintf("%d:%d", post.ID, post.UpdatedAt.UnixNano())
+	if html, ok := postRenderCache.get(key); ok {
+		return html
+	}
+
+	html := RenderMarkdown(post.Content)
+	postRenderCache.set(key, html)
+	return html
+}
+
+// PreviewMarkdown renvoie le rendu HTML du champ "content", pour l'aperçu en direct de l'éditeur
+func PreviewMarkdown(w http.ResponseWriter, r *http.Request) {
+	if GetUserIDFromRequest(r) == 0 {
+		http.Error(w, "Non authentifié", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPreviewSize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Contenu trop volumineux", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(RenderMarkdown(r.FormValue("content"))))
+}
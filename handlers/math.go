@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Longueur maximale d'une formule
+const maxFormulaLength = 2000
+
+// ValidateMath vérifie les formules $...$ et $$...$$ d'un contenu Markdown.
+// Les formules sont repérées par le même parser que le rendu : le code n'est pas concerné.
+func ValidateMath(content string) error {
+	doc := markdown.Parse(markdown.NormalizeNewlines([]byte(content)), parser.NewWithExtensions(markdownExtensions))
+
+	var err error
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Math:
+			err = validateFormula(string(n.Literal))
+		case *ast.MathBlock:
+			err = validateFormula(string(n.Literal))
+		case *ast.Text:
+			// Un $$ resté dans le texte est un bloc de formule jamais refermé
+			if strings.Contains(string(n.Literal), "$$") {
+				err = fmt.Errorf("formule non terminée : $$ sans $$ fermant")
+			}
+		}
+		if err != nil {
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+
+	return err
+}
+
+// validateFormula contrôle l'équilibre des accolades, des \begin/\end et des \left/\right
+func validateFormula(formula string) error {
+	trimmed := strings.TrimSpace(formula)
+	if trimmed == "" {
+		return fmt.Errorf("formule vide")
+	}
+	if len(trimmed) > maxFormulaLength {
+		return fmt.Errorf("formule trop longue (%d caractères maximum)", maxFormulaLength)
+	}
+
+	braces := 0
+	leftRight := 0
+	var environments []string
+
+	for i := 0; i < len(trimmed); i++ {
+		switch trimmed[i] {
+		case '{':
+			braces++
+		case '}':
+			braces--
+			if braces < 0 {
+				return fmt.Errorf("formule invalide « %s » : accolade fermante sans accolade ouvrante", trimmed)
+			}
+		case '\\':
+			command, next := readCommand(trimmed, i+1)
+			i = next - 1
+			switch command {
+			case "left":
+				leftRight++
+			case "right":
+				leftRight--
+				if leftRight < 0 {
+					return fmt.Errorf("formule invalide « %s » : \\right sans \\left", trimmed)
+				}
+			case "begin", "end":
+				name, end, ok := readGroup(trimmed, next)
+				if !ok {
+					return fmt.Errorf("formule invalide « %s » : nom d'environnement manquant après \\%s", trimmed, command)
+				}
+				i = end - 1
+				if command == "begin" {
+					environments = append(environments, name)
+				} else if len(environments) == 0 || environments[len(environments)-1] != name {
+					return fmt.Errorf("formule invalide « %s » : \\end{%s} inattendu", trimmed, name)
+				} else {
+					environments = environments[:len(environments)-1]
+				}
+			}
+		}
+	}
+
+	switch {
+	case braces > 0:
+		return fmt.Errorf("formule invalide « %s » : accolade non fermée", trimmed)
+	case leftRight > 0:
+		return fmt.Errorf("formule invalide « %s » : \\left sans \\right", trimmed)
+	case len(environments) > 0:
+		return fmt.Errorf("formule invalide « %s » : \\begin{%s} non fermé", trimmed, environments[len(environments)-1])
+	}
+
+	return nil
+}
+
+// readCommand lit le nom d'une commande après un antislash. Un symbole échappé
+// (\{, \}, \\, \$...) compte comme une commande d'un caractère.
+func readCommand(s string, start int) (string, int) {
+	end := start
+	for end < len(s) && unicode.IsLetter(rune(s[end])) {
+		end++
+	}
+	if end == start && end < len(s) {
+		end++
+	}
+	return s[start:end], end
+}
+
+// readGroup lit un argument entre accolades, par exemple {matrix}
+func readGroup(s string, start int) (string, int, bool) {
+	for start < len(s) && s[start] == ' ' {
+		start++
+	}
+	if start >= len(s) || s[start] != '{' {
+		return "", start, false
+	}
+	end := strings.IndexByte(s[start:], '}')
+	if end < 0 {
+		return "", start, false
+	}
+	return s[start+1 : start+end], start + end + 1, true
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import "testing"
+
+func TestValidateFormula(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+		wantErr bool
+	}{
+		{"accolades équilibrées", `\frac{a}{b^{2}}`, false},
+		{"accolade non fermée", `\frac{a}{b`, true},
+		{"accolade fermante en trop", `a}{b`, true},
+		{"accolades échappées", `\{ x \mid x > 0 \}`, false},
+		{"accolade échappée seule", `\{ x`, false},
+		{"antislash double puis accolade", `a \\ {b}`, false},
+		{"left et right", `\left( \frac{a}{b} \right)`, false},
+		{"left avec accolade échappée", `\left\{ x \right.`, false},
+		{"left sans right", `\left( x`, true},
+		{"right sans left", `x \right)`, true},
+		{"environnement", `\begin{pmatrix} a & b \end{pmatrix}`, false},
+		{"environnements imbriqués", `\begin{cases} \begin{matrix} a \end{matrix} \end{cases}`, false},
+		{"begin et end différents", `\begin{matrix} a \end{pmatrix}`, true},
+		{"end sans begin", `a \end{matrix}`, true},
+		{"begin non fermé", `\begin{matrix} a`, true},
+		{"nom d'environnement manquant", `\begin a`, true},
+		{"formule vide", `   `, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFormula(tt.formula)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFormula(%q) = %v, want error %v", tt.formula, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMath(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"sans formule", "Un prix de 5 € et rien d'autre", false},
+		{"formule en ligne", "On a $\\frac{a}{b}$ ici", false},
+		{"formule en ligne invalide", "On a $\\frac{a}{b$ ici", true},
+		{"bloc de formule", "$$\n\\begin{matrix} a \\end{matrix}\n$$", false},
+		{"bloc de formule invalide", "$$\n\\begin{matrix} a \\end{pmatrix}\n$$", true},
+		{"bloc de formule non fermé", "$$\nx^2\n\nLa suite", true},
+		{"dollar dans du code en ligne", "La variable `$$x{` du shell", false},
+		{"dollar dans un bloc de code", "```sh\necho $HOME ${PATH\necho $$\n```", false},
+		{"dollar dans un bloc de code indenté", "Du shell :\n\n    echo ${x\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMath(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMath(%q) = %v, want error %v", tt.content, err, tt.wantErr)
+			}
+		})
+	}
+}
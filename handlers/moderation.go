@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"fmt"
+	"forum/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ModerationHandler gère les signalements et la file de modération
+type ModerationHandler struct {
+	ReportStore   *models.ReportStore
+	PostStore     *models.PostStore
+	CommentStore  *models.CommentStore
+	UserStore     *models.UserStore
+	ActivityStore *models.ActivityStore
+}
+
+// NewModerationHandler crée une nouvelle instance de ModerationHandler
+func NewModerationHandler(reportStore *models.ReportStore, postStore *models.PostStore, commentStore *models.CommentStore, userStore *models.UserStore, activityStore *models.ActivityStore) *ModerationHandler {
+	return &ModerationHandler{
+		ReportStore:   reportStore,
+		PostStore:     postStore,
+		CommentStore:  commentStore,
+		UserStore:     userStore,
+		ActivityStore: activityStore,
+	}
+}
+
+// RegisterModerationRoutes enregistre les routes de signalement et de modération
+func RegisterModerationRoutes(r *mux.Router, h *ModerationHandler) {
+	r.HandleFunc("/post/{id:[0-9]+}/report", h.ReportPost).Methods("POST")
+	r.HandleFunc("/comment/{id:[0-9]+}/report", h.ReportComment).Methods("POST")
+
+	mod := r.PathPrefix("/moderation").Subrouter()
+	mod.Use(RequireRole(models.RoleModerator))
+	mod.HandleFunc("", h.ShowQueue).Methods("GET")
+	mod.HandleFunc("/reports/{id:[0-9]+}/{action}", h.ResolveReport).Methods("POST")
+	mod.HandleFunc("/posts/{id:[0-9]+}/{action:approve|reject}", h.ReviewPost).Methods("POST")
+	mod.HandleFunc("/comments/{id:[0-9]+}/{action:approve|reject}", h.ReviewComment).Methods("POST")
+}
+
+// ReportPost enregistre le signalement d'un post
+func (h *ModerationHandler) ReportPost(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+
+	h.createReport(w, r, post, 0)
+}
+
+// ReportComment enregistre le signalement d'un commentaire
+func (h *ModerationHandler) ReportComment(w http.ResponseWriter, r *http.Request) {
+	commentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de commentaire invalide", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.CommentStore.GetByID(commentID)
+	if err != nil || !comment.Status.IsPublic() {
+		http.Error(w, "Commentaire non trouvé", http.StatusNotFound)
+		return
+	}
+
+	post, err := h.PostStore.GetByID(comment.PostID)
+	if err != nil {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+
+	h.createReport(w, r, post, comment.ID)
+}
+
+func (h *ModerationHandler) createReport(w http.ResponseWriter, r *http.Request, post *models.Post, commentID int64) {
+	userID := GetUserIDFromRequest(r)
+	if userID == 0 {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	// Seul un contenu public peut être signalé : signaler un post en attente,
+	// rejeté ou dans la corbeille le rendrait visible à nouveau
+	if !post.Status.IsPublic() {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+
+	reasonValue, err := strconv.Atoi(r.FormValue("reason"))
+	reason := models.ReportReason(reasonValue)
+	if err != nil || !reason.IsValid() {
+		http.Error(w, "Raison de signalement invalide", http.StatusBadRequest)
+		return
+	}
+	description := strings.TrimSpace(r.FormValue("description"))
+
+	// Un seul signalement en attente par utilisateur et par cible
+	exists, err := h.ReportStore.HasPendingReport(userID, post.ID, commentID)
+	if err != nil {
+		log.Printf("Erreur lors de la vérification des signalements: %v", err)
+		http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+		return
+	}
+
+	if !exists {
+		report := &models.Report{
+			PostID:      post.ID,
+			CommentID:   commentID,
+			UserID:      userID,
+			Reason:      reason,
+			Description: description,
+		}
+		if err := h.ReportStore.Create(report); err != nil {
+			log.Printf("Erreur lors de la création du signalement: %v", err)
+			http.Error(w, "Erreur lors de l'enregistrement du signalement", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Signalement %d créé par l'utilisateur %d (post %d, commentaire %d)", report.ID, userID, post.ID, commentID)
+
+		// Un post signalé reste visible jusqu'à la décision d'un modérateur
+		if commentID == 0 {
+			if err := h.PostStore.MarkReported(post.ID); err != nil {
+				log.Printf("Erreur lors de la mise à jour du statut du post: %v", err)
+			}
+		}
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/post/%d", post.ID), http.StatusSeeOther)
+}
+
+// ShowQueue affiche la file des signalements en attente
+func (h *ModerationHandler) ShowQueue(w http.ResponseWriter, r *http.Request) {
+	pending, err := h.ReportStore.GetPending()
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des signalements: %v", err)
+		http.Error(w, "Erreur lors de la récupération des signalements", http.StatusInternalServerError)
+		return
+	}
+
+	resolved, err := h.ReportStore.GetRecentlyResolved(20)
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des signalements traités: %v", err)
+	}
+
+	// Contenus en attente de validation (pré-modération)
+	pendingPosts, err := h.PostStore.FilterPosts(models.PostFilter{Status: models.StatusPending, SortOrder: "asc"})
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des posts en attente: %v", err)
+	}
+	pendingComments, err := h.CommentStore.GetByStatus(models.StatusPending)
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des commentaires en attente: %v", err)
+	}
+
+	// Récupérer les cibles et les utilisateurs concernés
+	posts := make(map[int64]*models.Post)
+	comments := make(map[int64]*models.Comment)
+	users := make(map[int64]*models.User)
+	addUser := func(id int64) {
+		if _, exists := users[id]; id != 0 && !exists {
+			if user, err := h.UserStore.GetByID(id); err == nil {
+				users[id] = user
+			}
+		}
+	}
+	for _, post := range pendingPosts {
+		addUser(post.UserID)
+	}
+	for _, comment := range pendingComments {
+		addUser(comment.UserID)
+		if _, exists := posts[comment.PostID]; !exists {
+			if post, err := h.PostStore.GetByID(comment.PostID); err == nil {
+				posts[comment.PostID] = post
+			}
+		}
+	}
+	for _, report := range append(pending, resolved...) {
+		if _, exists := posts[report.PostID]; !exists {
+			if post, err := h.PostStore.GetByID(report.PostID); err == nil {
+				posts[report.PostID] = post
+			}
+		}
+		if report.CommentID != 0 {
+			if _, exists := comments[report.CommentID]; !exists {
+				if comment, err := h.CommentStore.GetByID(report.CommentID); err == nil {
+					comments[report.CommentID] = comment
+				}
+			}
+		}
+		addUser(report.UserID)
+		addUser(report.ModeratorID)
+	}
+
+	data := map[string]interface{}{
+		"User":            GetCurrentUser(r),
+		"IsAuthenticated": true,
+		"PendingReports":  pending,
+		"ResolvedReports": resolved,
+		"PendingPosts":    pendingPosts,
+		"PendingComments": pendingComments,
+		"ReportPosts":     posts,
+		"ReportComments":  comments,
+		"ReportUsers":     users,
+	}
+
+	RenderTemplate(w, r, "moderation.html", data)
+}
+
+// ResolveReport applique la décision d'un modérateur : hide, restore ou dismiss
+func (h *ModerationHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	moderator := GetCurrentUser(r)
+	vars := mux.Vars(r)
+
+	reportID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de signalement invalide", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.ReportStore.GetByID(reportID)
+	if err != nil {
+		http.Error(w, "Signalement non trouvé", http.StatusNotFound)
+		return
+	}
+	if report.Status != models.ReportPending {
+		http.Error(w, "Ce signalement a déjà été traité", http.StatusConflict)
+		return
+	}
+
+	note := strings.TrimSpace(r.FormValue("resolution_note"))
+	action := vars["action"]
+
+	var changeTarget bool
+	var reportStatus models.ReportStatus
+	switch action {
+	case "hide", "restore":
+		changeTarget = true
+		reportStatus = models.ReportResolved
+	case "dismiss":
+		reportStatus = models.ReportDismissed
+	default:
+		http.Error(w, "Action invalide", http.StatusBadRequest)
+		return
+	}
+
+	// Seul un contenu signalé ou masqué peut être rétabli, et un contenu
+	// supprimé entre-temps reste dans la corbeille
+	if changeTarget {
+		current, err := h.reportTargetStatus(report)
+		if err != nil {
+			http.Error(w, "Contenu signalé non trouvé", http.StatusNotFound)
+			return
+		}
+		if current == models.PostStatusDeleted ||
+			(action == "restore" && current != models.PostStatusReported && current != models.PostStatusHidden) {
+			changeTarget = false
+		}
+	}
+
+	// Masquer ou rétablir la cible clôt tous les signalements en attente sur celle-ci.
+	// Un contenu rétabli retrouve le statut qu'il avait avant le signalement.
+	reports := []*models.Report{report}
+	if changeTarget {
+		switch {
+		case report.IsCommentReport() && action == "hide":
+			err = h.CommentStore.Hide(report.CommentID)
+		case report.IsCommentReport():
+			err = h.CommentStore.Unhide(report.CommentID)
+		case action == "hide":
+			err = h.PostStore.Hide(report.PostID)
+		default:
+			err = h.PostStore.Unhide(report.PostID)
+		}
+		if err != nil {
+			log.Printf("Erreur lors de la mise à jour du statut de la cible: %v", err)
+			http.Error(w, "Erreur lors de la modération", http.StatusInternalServerError)
+			return
+		}
+
+		if targetReports, err := h.ReportStore.GetPendingForTarget(report.PostID, report.CommentID); err == nil {
+			reports = targetReports
+		}
+	}
+
+	for _, rep := range reports {
+		if err := h.ReportStore.Resolve(rep.ID, moderator.ID, reportStatus, note); err != nil {
+			log.Printf("Erreur lors de la clôture du signalement %d: %v", rep.ID, err)
+			continue
+		}
+		h.notifyReporter(moderator, rep, action, note)
+	}
+
+	// Un post signalé sans suite retrouve son statut s'il n'a plus de signalement en attente
+	if action == "dismiss" && !report.IsCommentReport() {
+		remaining, err := h.ReportStore.GetPendingForTarget(report.PostID, 0)
+		if err == nil && len(remaining) == 0 {
+			if post, err := h.PostStore.GetByID(report.PostID); err == nil && post.Status == models.PostStatusReported {
+				if err := h.PostStore.Unhide(post.ID); err != nil {
+					log.Printf("Erreur lors du rétablissement du post %d: %v", post.ID, err)
+				}
+			}
+		}
+	}
+
+	log.Printf("Signalement %d traité par le modérateur %d (%s)", report.ID, moderator.ID, action)
+	http.Redirect(w, r, "/moderation", http.StatusSeeOther)
+}
+
+// reportTargetStatus retourne le statut actuel du post ou du commentaire signalé
+func (h *ModerationHandler) reportTargetStatus(report *models.Report) (models.PostStatus, error) {
+	if report.IsCommentReport() {
+		comment, err := h.CommentStore.GetByID(report.CommentID)
+		if err != nil {
+			return "", err
+		}
+		return comment.Status, nil
+	}
+
+	post, err := h.PostStore.GetByID(report.PostID)
+	if err != nil {
+		return "", err
+	}
+	return post.Status, nil
+}
+
+// notifyReporter informe l'auteur du signalement de la décision prise
+func (h *ModerationHandler) notifyReporter(moderator *models.User, report *models.Report, action, note string) {
+	var decision string
+	switch action {
+	case "hide":
+		decision = "a masqué le contenu que vous avez signalé"
+	case "restore":
+		decision = "a examiné votre signalement et conservé le contenu"
+	default:
+		decision = "a classé votre signalement sans suite"
+	}
+	if note != "" {
+		decision += " : " + note
+	}
+
+	activity := &models.Activity{
+		UserID:      moderator.ID,
+		RecipientID: report.UserID,
+		Type:        models.ActivityReportHandled,
+		TargetID:    report.PostID,
+		CreatedAt:   time.Now(),
+		Content:     decision,
+		IsRead:      false,
+	}
+	if err := h.ActivityStore.Create(activity); err != nil {
+		log.Printf("Erreur lors de la notification du signalement %d: %v", report.ID, err)
+	}
+}
+
+// ReviewPost valide ou rejette un post en attente
+func (h *ModerationHandler) ReviewPost(w http.ResponseWriter, r *http.Request) {
+	moderator := GetCurrentUser(r)
+	vars := mux.Vars(r)
+
+	postID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+	if post.Status != models.StatusPending {
+		http.Error(w, "Ce post n'est pas en attente de validation", http.StatusConflict)
+		return
+	}
+
+	status := reviewStatus(vars["action"])
+	if err := h.PostStore.UpdateStatus(post.ID, status); err != nil {
+		log.Printf("Erreur lors de la validation du post %d: %v", post.ID, err)
+		http.Error(w, "Erreur lors de la modération", http.StatusInternalServerError)
+		return
+	}
+
+	note := strings.TrimSpace(r.FormValue("resolution_note"))
+	h.notifyAuthor(moderator, post.UserID, post.ID, fmt.Sprintf("votre post « %s »", post.Title), status, note)
+
+	log.Printf("Post %d passé au statut %s par le modérateur %d", post.ID, status, moderator.ID)
+	http.Redirect(w, r, "/moderation", http.StatusSeeOther)
+}
+
+// ReviewComment valide ou rejette un commentaire en attente
+func (h *ModerationHandler) ReviewComment(w http.ResponseWriter, r *http.Request) {
+	moderator := GetCurrentUser(r)
+	vars := mux.Vars(r)
+
+	commentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de commentaire invalide", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.CommentStore.GetByID(commentID)
+	if err != nil {
+		http.Error(w, "Commentaire non trouvé", http.StatusNotFound)
+		return
+	}
+	if comment.Status != models.StatusPending {
+		http.Error(w, "Ce commentaire n'est pas en attente de validation", http.StatusConflict)
+		return
+	}
+
+	status := reviewStatus(vars["action"])
+	if err := h.CommentStore.UpdateStatus(comment.ID, status); err != nil {
+		log.Printf("Erreur lors de la validation du commentaire %d: %v", comment.ID, err)
+		http.Error(w, "Erreur lors de la modération", http.StatusInternalServerError)
+		return
+	}
+
+	note := strings.TrimSpace(r.FormValue("resolution_note"))
+	h.notifyAuthor(moderator, comment.UserID, comment.PostID, "votre commentaire", status, note)
+
+	// Les notifications différées lors de la création sont envoyées maintenant
+	if status == models.StatusApproved {
+		if post, err := h.PostStore.GetByID(comment.PostID); err == nil {
+			var parent *models.Comment
+			if comment.ParentID != 0 {
+				parent, _ = h.CommentStore.GetByID(comment.ParentID)
+			}
+			notifyNewComment(h.ActivityStore, post, parent, comment)
+		}
+	}
+
+	log.Printf("Commentaire %d passé au statut %s par le modérateur %d", comment.ID, status, moderator.ID)
+	http.Redirect(w, r, "/moderation", http.StatusSeeOther)
+}
+
+// reviewStatus convertit l'action de validation en statut
+func reviewStatus(action string) models.PostStatus {
+	if action == "approve" {
+		return models.StatusApproved
+	}
+	return models.StatusRejected
+}
+
+// notifyAuthor informe l'auteur d'un contenu de la décision de pré-modération
+func (h *ModerationHandler) notifyAuthor(moderator *models.User, authorID, postID int64, subject string, status models.PostStatus, note string) {
+	content := "a approuvé " + subject
+	if status == models.StatusRejected {
+		content = "a refusé " + subject
+	}
+	if note != "" {
+		content += " : " + note
+	}
+
+	activity := &models.Activity{
+		UserID:      moderator.ID,
+		RecipientID: authorID,
+		Type:        models.ActivityModeration,
+		TargetID:    postID,
+		CreatedAt:   time.Now(),
+		Content:     content,
+		IsRead:      false,
+	}
+	if err := h.ActivityStore.Create(activity); err != nil {
+		log.Printf("Erreur lors de la notification de l'utilisateur %d: %v", authorID, err)
+	}
+}
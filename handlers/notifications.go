@@ -72,8 +72,12 @@ func (h *NotificationHandler) ShowNotifications(w http.ResponseWriter, r *http.R
 		}
 
 		// Ajouter des informations spécifiques en fonction du type d'activité
+		postActivity := activity.Type.IsReaction()
 		switch activity.Type {
-		case models.ActivityComment, models.ActivityLike, models.ActivityDislike:
+		case models.ActivityComment, models.ActivityReportHandled, models.ActivityModeration, models.ActivityReply, models.ActivityAnswer:
+			postActivity = true
+		}
+		if postActivity {
 			// Récupérer les détails du post concerné
 			post, err := h.PostStore.GetByID(activity.TargetID)
 			if err == nil {
@@ -99,7 +103,7 @@ func (h *NotificationHandler) ShowNotifications(w http.ResponseWriter, r *http.R
 	}
 
 	// Servir le template de notifications
-	RenderTemplate(w, "notifications.html", data)
+	RenderTemplate(w, r, "notifications.html", data)
 }
 
 // GetUnreadNotificationsCount retourne le nombre de notifications non lues
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -20,31 +21,25 @@ type PostHandler struct {
 	TagStore      *models.TagStore
 	CommentStore  *models.CommentStore
 	UserStore     *models.UserStore
-	LikeStore     *models.LikeStore
+	ReactionStore *models.ReactionStore
 	ActivityStore *models.ActivityStore
+	RevisionStore *models.RevisionStore
+	Moderation    *models.ModerationPolicy
 }
 
-func NewPostHandler(postStore *models.PostStore, tagStore *models.TagStore, commentStore *models.CommentStore, userStore *models.UserStore, likeStore *models.LikeStore, activityStore *models.ActivityStore) *PostHandler {
+func NewPostHandler(postStore *models.PostStore, tagStore *models.TagStore, commentStore *models.CommentStore, userStore *models.UserStore, reactionStore *models.ReactionStore, activityStore *models.ActivityStore, revisionStore *models.RevisionStore, moderation *models.ModerationPolicy) *PostHandler {
 	return &PostHandler{
 		PostStore:     postStore,
 		TagStore:      tagStore,
 		CommentStore:  commentStore,
 		UserStore:     userStore,
-		LikeStore:     likeStore,
+		ReactionStore: reactionStore,
 		ActivityStore: activityStore,
+		RevisionStore: revisionStore,
+		Moderation:    moderation,
 	}
 }
 
-func RegisterPostRoutes(r *mux.Router, h *PostHandler) {
-	r.HandleFunc("/", h.HomePage).Methods("GET")
-	r.HandleFunc("/post/{id}", h.ViewPost).Methods("GET")
-	r.HandleFunc("/create-post", h.NewPostPage).Methods("GET")
-	r.HandleFunc("/create-post", h.CreatePost).Methods("POST")
-	r.HandleFunc("/edit-post/{id}", h.EditPostPage).Methods("GET")
-	r.HandleFunc("/edit-post/{id}", h.UpdatePost).Methods("POST")
-	r.HandleFunc("/delete-post/{id}", h.DeletePost).Methods("POST")
-}
-
 // Page d'accueil avec liste des posts
 func (h *PostHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 	// Récupérer les paramètres de pagination
@@ -57,6 +52,7 @@ func (h *PostHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 	// Construire le filtre de recherche
 	filter := models.PostFilter{
 		SortOrder: "desc", // Par défaut, ordre décroissant
+		Statuses:  models.PublicPostStatuses,
 	}
 	filter.Pagination.Page = page
 	filter.Pagination.PerPage = perPage
@@ -68,8 +64,133 @@ func (h *PostHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Récupérer le tri
-	sortBy := r.URL.Query().Get("sort")
+	sortBy := applySort(&filter, r.URL.Query().Get("sort"))
+
+	// Filtrer les questions résolues ou non résolues
+	solved := r.URL.Query().Get("solved")
+	switch solved {
+	case models.SolvedOnly, models.UnsolvedOnly:
+		filter.Solved = solved
+	default:
+		solved = ""
+	}
+
+	// Filtrer par tags : inclus (tous ou au moins un) et exclus
+	tags := parseTagSelection(r.URL.Query(), &filter)
+
+	// Récupération des posts
+	posts, err := h.PostStore.FilterPosts(filter)
+	if err != nil {
+		http.Error(w, "Erreur lors de la récupération des posts", http.StatusInternalServerError)
+		return
+	}
+
+	authors, postTags, commentCounts := h.postListData(posts)
+
+	// Pagination
+	totalPosts, _ := h.PostStore.CountPosts(filter)
+	totalPages := (totalPosts + perPage - 1) / perPage
+
+	// Récupérer tous les tags pour le filtre
+	allTags, _ := h.TagStore.GetAllTags()
+	popularTags, _ := h.TagStore.GetPopularTags(10)
+
+	// Paramètres conservés par le nuage de tags et la pagination
+	baseParams := url.Values{}
+	baseParams.Set("sort", sortBy)
+	if searchQuery != "" {
+		baseParams.Set("search", searchQuery)
+	}
+	if solved != "" {
+		baseParams.Set("solved", solved)
+	}
+
+	// Les tags sélectionnés restent dans le nuage même s'ils ne sont pas populaires
+	tagsByID := make(map[int64]*models.Tag, len(allTags))
+	for _, tag := range allTags {
+		tagsByID[tag.ID] = tag
+	}
+	inCloud := make(map[int64]bool, len(popularTags))
+	for _, tag := range popularTags {
+		inCloud[tag.ID] = true
+	}
+	cloudTags := popularTags
+	for _, tagID := range append(append([]int64(nil), filter.Tags...), filter.ExcludeTags...) {
+		if tag, exists := tagsByID[tagID]; exists && !inCloud[tagID] {
+			cloudTags = append(cloudTags, tag)
+		}
+	}
+
+	var includedTags, excludedTags []*models.Tag
+	for _, tagID := range filter.Tags {
+		if tag, exists := tagsByID[tagID]; exists {
+			includedTags = append(includedTags, tag)
+		}
+	}
+	for _, tagID := range filter.ExcludeTags {
+		if tag, exists := tagsByID[tagID]; exists {
+			excludedTags = append(excludedTags, tag)
+		}
+	}
+
+	// Construire l'URL de base pour la pagination
+	paginationBaseURL := tags.url("/", baseParams)
+
+	// Liens de combinaison des tags inclus (tous / au moins un)
+	matchAll, matchAny := tags, tags
+	matchAll.Match = models.TagMatchAll
+	matchAny.Match = models.TagMatchAny
+
+	// Préparation des données pour le template
+	data := map[string]interface{}{
+		"Posts":             posts,
+		"Authors":           authors,
+		"PostTags":          postTags,
+		"CommentCounts":     commentCounts,
+		"AllTags":           allTags,
+		"PopularTags":       popularTags,
+		"CurrentPage":       page,
+		"TotalPages":        totalPages,
+		"TagCloud":          tags.toggles(cloudTags, "/", baseParams),
+		"IncludedTags":      includedTags,
+		"ExcludedTags":      excludedTags,
+		"TagMatch":          tags.Match,
+		"MatchAllURL":       matchAll.url("/", baseParams),
+		"MatchAnyURL":       matchAny.url("/", baseParams),
+		"ClearTagsURL":      tagSelection{}.url("/", baseParams),
+		"SearchQuery":       searchQuery,
+		"SortBy":            sortBy,
+		"Solved":            solved,
+		"PaginationBaseURL": paginationBaseURL,
+	}
+
+	// Vérification de l'authentification
+	userID := GetUserIDFromRequest(r)
+	if userID > 0 {
+		user, err := h.UserStore.GetByID(userID)
+		if err == nil {
+			data["CurrentUser"] = user
+			data["IsAuthenticated"] = true
+			data["User"] = user
+		}
+	} else {
+		data["IsAuthenticated"] = false
+	}
+
+	// Rendu du template
+	RenderTemplate(w, r, "index.html", data)
+}
+
+// applySort applique le paramètre de tri au filtre et retourne la valeur retenue.
+// Une recherche est triée par pertinence, sauf choix contraire.
+func applySort(filter *models.PostFilter, sortBy string) string {
+	if sortBy == "" && filter.Search != "" {
+		sortBy = "relevance"
+	}
 	switch sortBy {
+	case "relevance":
+		filter.SortBy = "relevance"
+		filter.SortOrder = "desc"
 	case "date_desc":
 		filter.SortBy = "date"
 		filter.SortOrder = "desc"
@@ -88,36 +209,39 @@ func (h *PostHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 	case "dislikes_asc":
 		filter.SortBy = "dislikes"
 		filter.SortOrder = "asc"
+	case "score_desc":
+		filter.SortBy = "score"
+		filter.SortOrder = "desc"
+	case "score_asc":
+		filter.SortBy = "score"
+		filter.SortOrder = "asc"
+	case "comments_desc":
+		filter.SortBy = "comments"
+		filter.SortOrder = "desc"
+	case "comments_asc":
+		filter.SortBy = "comments"
+		filter.SortOrder = "asc"
+	case "activity_desc":
+		filter.SortBy = "activity"
+		filter.SortOrder = "desc"
+	case "activity_asc":
+		filter.SortBy = "activity"
+		filter.SortOrder = "asc"
+	case "hot":
+		filter.SortBy = "hot"
+		filter.SortOrder = "desc"
 	default:
 		// Par défaut, trier par date (plus récent)
 		sortBy = "date_desc"
 		filter.SortBy = "date"
 		filter.SortOrder = "desc"
 	}
+	return sortBy
+}
 
-	// Filtrer par tag si spécifié
-	var currentTagName string
-	tagID := r.URL.Query().Get("tag")
-	if tagID != "" {
-		tagIDInt, err := strconv.ParseInt(tagID, 10, 64)
-		if err == nil {
-			filter.Tag = tagIDInt
-
-			// Récupérer le nom du tag pour l'affichage
-			tag, err := h.TagStore.GetByID(tagIDInt)
-			if err == nil {
-				currentTagName = tag.Name
-			}
-		}
-	}
-
-	// Récupération des posts
-	posts, err := h.PostStore.FilterPosts(filter)
-	if err != nil {
-		http.Error(w, "Erreur lors de la récupération des posts", http.StatusInternalServerError)
-		return
-	}
-
+// postListData charge les auteurs, les tags et le nombre de commentaires
+// des posts d'une liste, pour le template post_card
+func (h *PostHandler) postListData(posts []*models.Post) (map[int64]*models.User, map[int64][]*models.Tag, map[int64]int) {
 	// Récupération des auteurs
 	authors := make(map[int64]*models.User)
 	for _, post := range posts {
@@ -138,66 +262,18 @@ func (h *PostHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Récupérer le nombre de commentaires pour chaque post
+	// Récupérer le nombre de commentaires publics pour chaque post
 	commentCounts := make(map[int64]int)
 	for _, post := range posts {
-		count, err := h.CommentStore.GetCommentsByPostID(post.ID)
+		count, err := h.PostStore.GetCommentCount(post.ID)
 		if err == nil {
-			commentCounts[post.ID] = len(count)
+			commentCounts[post.ID] = count
 		} else {
 			commentCounts[post.ID] = 0
 		}
 	}
 
-	// Pagination
-	totalPosts, _ := h.PostStore.CountPosts(filter)
-	totalPages := (totalPosts + perPage - 1) / perPage
-
-	// Récupérer tous les tags pour le filtre
-	allTags, _ := h.TagStore.GetAllTags()
-	popularTags, _ := h.TagStore.GetPopularTags(10)
-
-	// Construire l'URL de base pour la pagination
-	paginationBaseURL := "/?sort=" + sortBy
-	if tagID != "" {
-		paginationBaseURL += "&tag=" + tagID
-	}
-	if searchQuery != "" {
-		paginationBaseURL += "&search=" + searchQuery
-	}
-
-	// Préparation des données pour le template
-	data := map[string]interface{}{
-		"Posts":             posts,
-		"Authors":           authors,
-		"PostTags":          postTags,
-		"CommentCounts":     commentCounts,
-		"AllTags":           allTags,
-		"PopularTags":       popularTags,
-		"CurrentPage":       page,
-		"TotalPages":        totalPages,
-		"CurrentTagID":      filter.Tag,
-		"CurrentTagName":    currentTagName,
-		"SearchQuery":       searchQuery,
-		"SortBy":            sortBy,
-		"PaginationBaseURL": paginationBaseURL,
-	}
-
-	// Vérification de l'authentification
-	userID := getUserIDFromCookie(r)
-	if userID > 0 {
-		user, err := h.UserStore.GetByID(userID)
-		if err == nil {
-			data["CurrentUser"] = user
-			data["IsAuthenticated"] = true
-			data["User"] = user
-		}
-	} else {
-		data["IsAuthenticated"] = false
-	}
-
-	// Rendu du template
-	RenderTemplate(w, "index.html", data)
+	return authors, postTags, commentCounts
 }
 
 // Affichage d'un post spécifique
@@ -217,7 +293,26 @@ func (h *PostHandler) ViewPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Un post masqué, en attente ou rejeté n'est visible que par son auteur et les modérateurs
+	if !post.Status.IsPublic() && !canEditPost(r, post) {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+
 	comments, _ := h.CommentStore.GetCommentsByPostID(postID)
+
+	// Les commentaires masqués ne sont affichés qu'aux modérateurs,
+	// ceux en attente ou rejetés également à leur auteur
+	if currentUser := GetCurrentUser(r); currentUser == nil || !currentUser.IsModerator() {
+		visible := make([]*models.Comment, 0, len(comments))
+		for _, comment := range comments {
+			// Les commentaires supprimés restent dans le fil sous forme d'emplacement vide
+			if comment.VisibleTo(currentUser) || comment.Status == models.PostStatusDeleted {
+				visible = append(visible, comment)
+			}
+		}
+		comments = visible
+	}
 	author, _ := h.UserStore.GetByID(post.UserID)
 	tags, _ := h.TagStore.GetTagsByPostID(postID)
 
@@ -232,31 +327,73 @@ func (h *PostHandler) ViewPost(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Récupérer les likes/dislikes
-	userID := getUserIDFromCookie(r)
-	var userLike *models.Like
-	commentLikes := make(map[int64]*models.Like)
+	// Récupérer les réactions, par type, et celles de l'utilisateur
+	if counts, err := h.ReactionStore.Counts(models.PostReactions, postID); err == nil {
+		post.Reactions = counts
+	}
+	commentIDs := make([]int64, len(comments))
+	for i, comment := range comments {
+		commentIDs[i] = comment.ID
+	}
+	if counts, err := h.ReactionStore.CountsFor(models.CommentReactions, commentIDs); err == nil {
+		for _, comment := range comments {
+			comment.Reactions = counts[comment.ID]
+		}
+	}
 
+	userID := GetUserIDFromRequest(r)
+	var userReactions models.UserReactions
+	var commentReactions map[int64]models.UserReactions
 	if userID > 0 {
-		userLike, _ = h.LikeStore.GetByPostAndUser(postID, userID)
+		userReactions, _ = h.ReactionStore.UserReactions(models.PostReactions, postID, userID)
+		commentReactions, _ = h.ReactionStore.UserCommentReactions(postID, userID)
+	}
 
+	// La réponse acceptée est épinglée en tête des commentaires
+	var acceptedComment *models.Comment
+	if post.IsSolved() {
 		for _, comment := range comments {
-			like, _ := h.LikeStore.GetByCommentAndUser(comment.ID, userID)
-			if like != nil {
-				commentLikes[comment.ID] = like
+			if comment.ID == post.AcceptedCommentID && comment.Status.IsPublic() {
+				acceptedComment = comment
+				break
 			}
 		}
 	}
 
+	// Les commentaires supprimés ne comptent pas
+	commentCount := 0
+	for _, comment := range comments {
+		if comment.Status != models.PostStatusDeleted {
+			commentCount++
+		}
+	}
+
+	// Dernière modification, pour la mention « modifié »
+	var lastEdit *models.PostRevision
+	var lastEditor *models.User
+	if rev, err := h.RevisionStore.Latest(postID); err == nil && rev != nil {
+		lastEdit = rev
+		lastEditor, _ = h.UserStore.GetByID(rev.UserID)
+	}
+
 	// Préparation des données pour le template
 	data := map[string]interface{}{
-		"Post":           post,
-		"Author":         author,
-		"Comments":       comments,
-		"Tags":           tags,
-		"CommentAuthors": commentAuthors,
-		"UserLike":       userLike,
-		"CommentLikes":   commentLikes,
+		"Post":             post,
+		"LastEdit":         lastEdit,
+		"LastEditor":       lastEditor,
+		"PostHTML":         RenderPost(post),
+		"Author":           author,
+		"AcceptedComment":  acceptedComment,
+		"CanAccept":        post.IsQuestion() && canEditPost(r, post),
+		"Comments":         models.BuildCommentTree(comments, models.CommentMaxDepth),
+		"CommentCount":     commentCount,
+		"MaxDepth":         models.CommentMaxDepth,
+		"Tags":             tags,
+		"CommentAuthors":   commentAuthors,
+		"ReactionTypes":    h.ReactionStore.Registry.Extra(),
+		"AllReactionTypes": h.ReactionStore.Registry.Types(),
+		"UserReactions":    userReactions,
+		"CommentReactions": commentReactions,
 	}
 
 	// Vérification de l'authentification
@@ -270,12 +407,12 @@ func (h *PostHandler) ViewPost(w http.ResponseWriter, r *http.Request) {
 		data["IsAuthenticated"] = false
 	}
 
-	RenderTemplate(w, "post_view.html", data)
+	RenderTemplate(w, r, "post_view.html", data)
 }
 
 // Page de création de post
 func (h *PostHandler) NewPostPage(w http.ResponseWriter, r *http.Request) {
-	userID := getUserIDFromCookie(r)
+	userID := GetUserIDFromRequest(r)
 	if userID == 0 {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
@@ -292,7 +429,7 @@ func (h *PostHandler) NewPostPage(w http.ResponseWriter, r *http.Request) {
 		"User":        user,
 	}
 
-	RenderTemplate(w, "post_forms.html", data)
+	RenderTemplate(w, r, "post_forms.html", data)
 }
 
 // Création d'un nouveau post
@@ -303,7 +440,7 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Vérification de l'authentification
-	userID := getUserIDFromCookie(r)
+	userID := GetUserIDFromRequest(r)
 	if userID <= 0 {
 		http.Error(w, "Non authentifié", http.StatusUnauthorized)
 		return
@@ -327,13 +464,19 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := ValidateMath(content); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Création du post
 	post := &models.Post{
 		UserID:    userID,
 		Title:     title,
 		Content:   content,
 		CreatedAt: time.Now(),
-		Status:    models.StatusApproved,
+		Status:    h.Moderation.InitialStatus(GetCurrentUser(r)),
+		Type:      models.ParsePostType(r.FormValue("type")),
 	}
 
 	// Traitement de l'image
@@ -429,11 +572,15 @@ func (h *PostHandler) EditPostPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Vérification des permissions
-	userID := getUserIDFromCookie(r)
-	if userID != post.UserID {
+	userID := GetUserIDFromRequest(r)
+	if !canEditPost(r, post) {
 		http.Error(w, "Vous n'êtes pas autorisé à modifier ce post", http.StatusForbidden)
 		return
 	}
+	if post.Status == models.PostStatusDeleted {
+		http.Error(w, "Ce post est dans la corbeille", http.StatusConflict)
+		return
+	}
 
 	// Récupération des tags
 	postTags, _ := h.TagStore.GetTagsByPostID(postID)
@@ -460,7 +607,7 @@ func (h *PostHandler) EditPostPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	RenderTemplate(w, "post_forms.html", data)
+	RenderTemplate(w, r, "post_forms.html", data)
 }
 
 // Mise à jour d'un post
@@ -485,11 +632,15 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Vérification des permissions
-	userID := getUserIDFromCookie(r)
-	if userID != post.UserID {
+	userID := GetUserIDFromRequest(r)
+	if !canEditPost(r, post) {
 		http.Error(w, "Vous n'êtes pas autorisé à modifier ce post", http.StatusForbidden)
 		return
 	}
+	if post.Status == models.PostStatusDeleted {
+		http.Error(w, "Ce post est dans la corbeille", http.StatusConflict)
+		return
+	}
 
 	// Traitement du formulaire
 	err = r.ParseMultipartForm(10 << 20)
@@ -509,9 +660,16 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Mise à jour des données du post
+	if err := ValidateMath(content); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Mise à jour des données du post, en gardant la version précédente pour l'historique
+	before := *post
 	post.Title = title
 	post.Content = content
+	post.Type = models.ParsePostType(r.FormValue("type"))
 	post.UpdatedAt = time.Now()
 
 	// Traitement de l'image
@@ -555,8 +713,13 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		post.ImageType = contentType
 	}
 
-	// Sauvegarde des modifications
-	if err := h.PostStore.Update(post); err != nil {
+	// Une modification du texte repasse par la pré-modération
+	if before.Title != post.Title || before.Content != post.Content {
+		post.Status = h.Moderation.EditStatus(GetCurrentUser(r), post.Status)
+	}
+
+	// Sauvegarde des modifications et de la nouvelle version
+	if err := h.PostStore.UpdateWithRevision(&before, post, userID); err != nil {
 		http.Error(w, "Erreur lors de la mise à jour du post: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -615,19 +778,19 @@ func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Vérification des permissions
-	userID := getUserIDFromCookie(r)
+	userID := GetUserIDFromRequest(r)
 	if userID == 0 {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
 	post, err := h.PostStore.GetByID(postID)
-	if err != nil || post.UserID != userID {
+	if err != nil || !canEditPost(r, post) {
 		http.Error(w, "Non autorisé", http.StatusForbidden)
 		return
 	}
 
-	// Suppression du post
+	// Mise à la corbeille du post
 	if err := h.PostStore.Delete(postID); err != nil {
 		http.Error(w, "Erreur lors de la suppression", http.StatusInternalServerError)
 		return
@@ -635,3 +798,39 @@ func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
+
+// Restauration d'un post depuis la corbeille
+func (h *PostHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+
+	if GetUserIDFromRequest(r) == 0 {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil || !canEditPost(r, post) {
+		http.Error(w, "Non autorisé", http.StatusForbidden)
+		return
+	}
+
+	if post.Status != models.PostStatusDeleted {
+		http.Redirect(w, r, fmt.Sprintf("/post/%d", postID), http.StatusSeeOther)
+		return
+	}
+	if time.Now().After(post.RestoreDeadline()) {
+		http.Error(w, "Le délai de restauration de ce post est dépassé", http.StatusGone)
+		return
+	}
+
+	if err := h.PostStore.Restore(postID); err != nil {
+		http.Error(w, "Erreur lors de la restauration", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/post/%d", postID), http.StatusSeeOther)
+}
@@ -20,37 +20,30 @@ import (
 )
 
 type ProfileHandler struct {
-	UserStore *models.UserStore
-	PostStore *models.PostStore
+	UserStore     *models.UserStore
+	PostStore     *models.PostStore
+	SessionStore  *models.SessionStore
+	APITokenStore *models.APITokenStore
 }
 
-func NewProfileHandler(userStore *models.UserStore, postStore *models.PostStore) *ProfileHandler {
+func NewProfileHandler(userStore *models.UserStore, postStore *models.PostStore, sessionStore *models.SessionStore, apiTokenStore *models.APITokenStore) *ProfileHandler {
 	return &ProfileHandler{
-		UserStore: userStore,
-		PostStore: postStore,
+		UserStore:     userStore,
+		PostStore:     postStore,
+		SessionStore:  sessionStore,
+		APITokenStore: apiTokenStore,
 	}
 }
 
-// RegisterProfileRoutes enregistre toutes les routes liées au profil
-func RegisterProfileRoutes(r *mux.Router, auth *AuthHandler) {
-	userStore := models.NewUserStore(database.GetDB())
-	postStore := models.NewPostStore(database.GetDB())
-	h := NewProfileHandler(userStore, postStore)
-
-	// Groupe de routes protégées par authentification
-	profileRoutes := r.PathPrefix("").Subrouter()
-	profileRoutes.Use(auth.authMiddleware())
-
-	// Routes du profil
-	profileRoutes.HandleFunc("/user/{id:[0-9]+}", h.ShowUserProfile).Methods("GET")
-	profileRoutes.HandleFunc("/profile", h.ShowProfile).Methods("GET")
-	profileRoutes.HandleFunc("/upload-avatar", h.UploadAvatar).Methods("POST")
-	profileRoutes.HandleFunc("/update-profile", h.UpdateProfile).Methods("POST")
+func (h *ProfileHandler) ShowProfile(w http.ResponseWriter, r *http.Request) {
+	h.renderProfile(w, r, nil)
 }
 
-func (h *ProfileHandler) ShowProfile(w http.ResponseWriter, r *http.Request) {
+// renderProfile affiche le profil de l'utilisateur connecté ; extra complète
+// les données du template
+func (h *ProfileHandler) renderProfile(w http.ResponseWriter, r *http.Request, extra map[string]interface{}) {
 	// Récupérer l'ID de l'utilisateur connecté
-	userID := getUserIDFromCookie(r)
+	userID := GetUserIDFromRequest(r)
 	if userID == 0 {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
@@ -84,8 +77,8 @@ func (h *ProfileHandler) ShowProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Récupération des posts aimés par l'utilisateur
-	likeStore := models.NewLikeStore(database.GetDB())
-	likedPostIDs, err := likeStore.GetLikedPostIDs(userID)
+	reactionStore := models.NewReactionStore(database.GetDB(), nil)
+	likedPostIDs, err := reactionStore.GetLikedPostIDs(userID)
 	if err != nil {
 		log.Printf("Erreur lors de la récupération des posts aimés: %v", err)
 		// Continuer même en cas d'erreur
@@ -123,9 +116,9 @@ func (h *ProfileHandler) ShowProfile(w http.ResponseWriter, r *http.Request) {
 
 	// Pour les posts créés
 	for _, post := range posts {
-		comments, err := commentStore.GetCommentsByPostID(post.ID)
+		count, err := postStore.GetCommentCount(post.ID)
 		if err == nil {
-			commentCounts[post.ID] = len(comments)
+			commentCounts[post.ID] = count
 		} else {
 			commentCounts[post.ID] = 0
 		}
@@ -134,9 +127,9 @@ func (h *ProfileHandler) ShowProfile(w http.ResponseWriter, r *http.Request) {
 	// Pour les posts aimés
 	for _, post := range likedPosts {
 		if _, exists := commentCounts[post.ID]; !exists {
-			comments, err := commentStore.GetCommentsByPostID(post.ID)
+			count, err := postStore.GetCommentCount(post.ID)
 			if err == nil {
-				commentCounts[post.ID] = len(comments)
+				commentCounts[post.ID] = count
 			} else {
 				commentCounts[post.ID] = 0
 			}
@@ -156,19 +149,49 @@ func (h *ProfileHandler) ShowProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Préparation des données pour le template
-	data := map[string]interface{}{
-		"User":            user,
-		"Posts":           posts,
-		"LikedPosts":      likedPosts,
-		"Authors":         authors,
-		"CommentCounts":   commentCounts,
-		"IsAuthenticated": true,
-		"TotalPosts":      totalPosts,
-		"TotalComments":   totalComments,
+	// Récupération des sessions actives
+	sessions, err := h.SessionStore.GetByUserID(userID)
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des sessions: %v", err)
+	}
+	var currentSessionID int64
+	if session := GetCurrentSession(r); session != nil {
+		currentSessionID = session.ID
 	}
 
-	RenderTemplate(w, "profile.html", data)
+	// Corbeille : posts supprimés encore restaurables
+	trashedPosts, err := postStore.GetDeletedByUserID(userID)
+	if err != nil {
+		log.Printf("Erreur lors de la récupération de la corbeille: %v", err)
+	}
+
+	// Jetons d'API personnels
+	apiTokens, err := h.APITokenStore.GetByUserID(userID)
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des jetons d'API: %v", err)
+	}
+
+	// Préparation des données pour le template
+	data := map[string]interface{}{
+		"User":             user,
+		"Posts":            posts,
+		"TrashedPosts":     trashedPosts,
+		"TrashRetention":   int(models.TrashRetention.Hours() / 24),
+		"LikedPosts":       likedPosts,
+		"Authors":          authors,
+		"CommentCounts":    commentCounts,
+		"IsAuthenticated":  true,
+		"TotalPosts":       totalPosts,
+		"TotalComments":    totalComments,
+		"Sessions":         sessions,
+		"CurrentSessionID": currentSessionID,
+		"APITokens":        apiTokens,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+
+	RenderTemplate(w, r, "profile.html", data)
 }
 
 // ShowUserProfile - Mise à jour pour inclure les statistiques
@@ -206,6 +229,7 @@ func (h *ProfileHandler) ShowUserProfile(w http.ResponseWriter, r *http.Request)
 		UserID:    userID,
 		SortBy:    "date",
 		SortOrder: "desc",
+		Statuses:  models.PublicPostStatuses,
 	}
 	filter.Pagination.Page = page
 	filter.Pagination.PerPage = perPage
@@ -227,9 +251,9 @@ func (h *ProfileHandler) ShowUserProfile(w http.ResponseWriter, r *http.Request)
 
 	// Compter les commentaires pour chaque post
 	for _, post := range posts {
-		comments, err := commentStore.GetCommentsByPostID(post.ID)
+		count, err := h.PostStore.GetCommentCount(post.ID)
 		if err == nil {
-			commentCounts[post.ID] = len(comments)
+			commentCounts[post.ID] = count
 		} else {
 			commentCounts[post.ID] = 0
 		}
@@ -242,7 +266,7 @@ func (h *ProfileHandler) ShowUserProfile(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Vérifier si l'utilisateur actuel est authentifié
-	currentUserID := getUserIDFromCookie(r)
+	currentUserID := GetUserIDFromRequest(r)
 	isAuthenticated := currentUserID > 0
 	isOwnProfile := currentUserID == userID
 
@@ -258,12 +282,12 @@ func (h *ProfileHandler) ShowUserProfile(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Utiliser le template du profil public
-	RenderTemplate(w, "user_profile.html", data)
+	RenderTemplate(w, r, "user_profile.html", data)
 }
 
 // UploadAvatar gère le téléchargement d'avatar et le recadrage en carré
 func (h *ProfileHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
-	userID := getUserIDFromCookie(r)
+	userID := GetUserIDFromRequest(r)
 	if userID == 0 {
 		http.Error(w, "Non autorisé", http.StatusUnauthorized)
 		return
@@ -385,7 +409,7 @@ func cropToSquare(img image.Image) image.Image {
 
 // UpdateProfile gère la mise à jour des infos
 func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
-	userID := getUserIDFromCookie(r)
+	userID := GetUserIDFromRequest(r)
 	if userID == 0 {
 		http.Error(w, "Non autorisé", http.StatusUnauthorized)
 		return
@@ -430,6 +454,63 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Case non cochée : le champ est absent du formulaire
+	if err := h.UserStore.SetAnonymousDislikes(userID, r.FormValue("anonymous_dislikes") == "1"); err != nil {
+		log.Printf("Erreur lors de la mise à jour des préférences: %v", err)
+		http.Error(w, "Erreur de mise à jour", http.StatusInternalServerError)
+		return
+	}
+
 	log.Printf("Profil mis à jour avec succès pour l'utilisateur %d", userID)
 	http.Redirect(w, r, "/profile", http.StatusSeeOther)
 }
+
+// RevokeSession révoque une session précise de l'utilisateur connecté
+func (h *ProfileHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromRequest(r)
+	if userID == 0 {
+		http.Error(w, "Non autorisé", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de session invalide", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.SessionStore.DeleteByID(sessionID, userID); err != nil {
+		log.Printf("Erreur lors de la révocation de la session %d: %v", sessionID, err)
+		http.Error(w, "Session non trouvée", http.StatusNotFound)
+		return
+	}
+
+	// Révoquer la session courante revient à se déconnecter
+	if current := GetCurrentSession(r); current != nil && current.ID == sessionID {
+		clearSessionCookie(w)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	log.Printf("Session %d révoquée pour l'utilisateur %d", sessionID, userID)
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+// RevokeAllSessions déconnecte l'utilisateur de tous ses appareils
+func (h *ProfileHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromRequest(r)
+	if userID == 0 {
+		http.Error(w, "Non autorisé", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.SessionStore.DeleteByUserID(userID); err != nil {
+		log.Printf("Erreur lors de la révocation des sessions: %v", err)
+		http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Toutes les sessions de l'utilisateur %d ont été révoquées", userID)
+	clearSessionCookie(w)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
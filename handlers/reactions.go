@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/json"
+	"forum/models"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ReactionHandler struct {
+	ReactionStore *models.ReactionStore
+	PostStore     *models.PostStore
+	CommentStore  *models.CommentStore
+	ActivityStore *models.ActivityStore
+}
+
+// NewReactionHandler crée une nouvelle instance de ReactionHandler
+func NewReactionHandler(reactionStore *models.ReactionStore, postStore *models.PostStore, commentStore *models.CommentStore, activityStore *models.ActivityStore) *ReactionHandler {
+	return &ReactionHandler{
+		ReactionStore: reactionStore,
+		PostStore:     postStore,
+		CommentStore:  commentStore,
+		ActivityStore: activityStore,
+	}
+}
+
+// Nombre d'utilisateurs par page dans les listes « qui a réagi »
+const reactorsPerPage = 20
+
+// RegisterReactionRoutes enregistre les routes des réactions. L'action est la
+// clé d'une réaction du registre (like, dislike, helpful...), qui est ajoutée
+// ou retirée si elle était déjà posée, ou remove pour retirer le vote.
+func RegisterReactionRoutes(r *mux.Router, h *ReactionHandler) {
+	r.HandleFunc("/api/post/{id}/reactions/{type}", h.PostReactors).Methods("GET")
+	r.HandleFunc("/api/comment/{id}/reactions/{type}", h.CommentReactors).Methods("GET")
+	r.HandleFunc("/api/post/{id}/{action}", h.ReactToPost).Methods("POST")
+	r.HandleFunc("/api/comment/{id}/{action}", h.ReactToComment).Methods("POST")
+}
+
+// ReactToPost gère les réactions aux posts
+func (h *ReactionHandler) ReactToPost(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromRequest(r)
+	if userID == 0 {
+		http.Error(w, "Vous devez être connecté", http.StatusUnauthorized)
+		return
+	}
+
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+
+	// On ne réagit qu'aux posts visibles, hors corbeille
+	post, ok := h.visiblePost(r, postID)
+	if !ok || post.Status == models.PostStatusDeleted {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+
+	counts, reaction, added, ok := h.react(w, r, models.PostReactions, postID, userID)
+	if !ok {
+		return
+	}
+
+	// Notifier l'auteur du post des nouvelles réactions
+	if added && post.UserID != userID {
+		h.notify(GetCurrentUser(r), post.UserID, postID, reaction, reactionMessage(reaction, "votre post"))
+	}
+
+	h.writeReactions(w, models.PostReactions, postID, userID, counts)
+}
+
+// ReactToComment gère les réactions aux commentaires
+func (h *ReactionHandler) ReactToComment(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromRequest(r)
+	if userID == 0 {
+		http.Error(w, "Vous devez être connecté", http.StatusUnauthorized)
+		return
+	}
+
+	commentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de commentaire invalide", http.StatusBadRequest)
+		return
+	}
+
+	// On ne réagit qu'aux commentaires visibles, ni supprimés ni sous un post à la corbeille
+	comment, post, ok := h.visibleComment(r, commentID)
+	if !ok || comment.Status == models.PostStatusDeleted || post.Status == models.PostStatusDeleted {
+		http.Error(w, "Commentaire non trouvé", http.StatusNotFound)
+		return
+	}
+
+	counts, reaction, added, ok := h.react(w, r, models.CommentReactions, commentID, userID)
+	if !ok {
+		return
+	}
+
+	// La notification pointe vers le post du commentaire
+	if added && comment.UserID != userID {
+		h.notify(GetCurrentUser(r), comment.UserID, comment.PostID, reaction, reactionMessage(reaction, "votre commentaire sur"))
+	}
+
+	h.writeReactions(w, models.CommentReactions, commentID, userID, counts)
+}
+
+// react applique l'action demandée. added indique qu'une réaction vient
+// d'être posée ; ok est faux si une erreur a déjà été renvoyée au client.
+func (h *ReactionHandler) react(w http.ResponseWriter, r *http.Request, target models.ReactionTarget, targetID, userID int64) (counts models.ReactionCounts, reaction models.ReactionType, added, ok bool) {
+	action := mux.Vars(r)["action"]
+
+	var err error
+	if action == "remove" {
+		counts, err = h.ReactionStore.ClearVote(target, targetID, userID)
+	} else {
+		var known bool
+		if reaction, known = h.ReactionStore.Registry.Get(action); !known {
+			http.Error(w, "Réaction invalide", http.StatusBadRequest)
+			return nil, reaction, false, false
+		}
+		counts, added, err = h.ReactionStore.Toggle(target, targetID, userID, reaction)
+	}
+
+	if err != nil {
+		log.Printf("Erreur lors du traitement de la réaction: %v", err)
+		http.Error(w, "Erreur lors du traitement de la réaction", http.StatusInternalServerError)
+		return nil, reaction, false, false
+	}
+	return counts, reaction, added, true
+}
+
+// reactionMessage construit le texte d'une notification de réaction ; subject
+// désigne le contenu réagi (« votre post »)
+func reactionMessage(reaction models.ReactionType, subject string) string {
+	switch reaction.Key {
+	case models.ReactionLike:
+		return "a aimé " + subject
+	case models.ReactionDislike:
+		return "n'a pas aimé " + subject
+	default:
+		return "a réagi « " + reaction.Emoji + " " + reaction.Label + " » à " + subject
+	}
+}
+
+// notify crée la notification d'une réaction pour l'auteur du contenu. Les
+// dislikes d'un utilisateur qui les garde anonymes ne sont pas notifiés : la
+// notification afficherait son nom.
+func (h *ReactionHandler) notify(actor *models.User, recipientID, postID int64, reaction models.ReactionType, content string) {
+	if reaction.Key == models.ReactionDislike && actor.AnonymousDislikes {
+		return
+	}
+
+	activity := &models.Activity{
+		UserID:      actor.ID,
+		RecipientID: recipientID,
+		Type:        reaction.ActivityType(),
+		TargetID:    postID,
+		Content:     content,
+	}
+	if err := h.ActivityStore.Create(activity); err != nil {
+		log.Printf("Erreur lors de la création de la notification: %v", err)
+	}
+}
+
+// writeReactions renvoie les compteurs de la cible et les réactions de l'utilisateur
+func (h *ReactionHandler) writeReactions(w http.ResponseWriter, target models.ReactionTarget, targetID, userID int64, counts models.ReactionCounts) {
+	userReactions, err := h.ReactionStore.UserReactions(target, targetID, userID)
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des réactions: %v", err)
+	}
+
+	active := make([]string, 0, len(userReactions))
+	for _, t := range h.ReactionStore.Registry.Types() {
+		if userReactions[t.Key] {
+			active = append(active, t.Key)
+		}
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"likes":      counts.Likes(),
+		"dislikes":   counts.Dislikes(),
+		"counts":     counts,
+		"userAction": userReactions.Vote(),
+		"reactions":  active,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// PostReactors liste les utilisateurs ayant posé une réaction sur un post
+func (h *ReactionHandler) PostReactors(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.visiblePost(r, postID); !ok {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+
+	h.writeReactors(w, r, models.PostReactions, postID)
+}
+
+// CommentReactors liste les utilisateurs ayant posé une réaction sur un commentaire
+func (h *ReactionHandler) CommentReactors(w http.ResponseWriter, r *http.Request) {
+	commentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de commentaire invalide", http.StatusBadRequest)
+		return
+	}
+
+	if _, _, ok := h.visibleComment(r, commentID); !ok {
+		http.Error(w, "Commentaire non trouvé", http.StatusNotFound)
+		return
+	}
+
+	h.writeReactors(w, r, models.CommentReactions, commentID)
+}
+
+// visiblePost charge un post s'il est visible par l'utilisateur courant
+func (h *ReactionHandler) visiblePost(r *http.Request, postID int64) (*models.Post, bool) {
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil || (!post.Status.IsPublic() && !canEditPost(r, post)) {
+		return nil, false
+	}
+	return post, true
+}
+
+// visibleComment charge un commentaire et son post avec les mêmes règles de
+// visibilité que dans le fil de discussion : le post doit être visible, et le
+// commentaire visible par l'utilisateur (voir Comment.VisibleTo)
+func (h *ReactionHandler) visibleComment(r *http.Request, commentID int64) (*models.Comment, *models.Post, bool) {
+	comment, err := h.CommentStore.GetByID(commentID)
+	if err != nil {
+		return nil, nil, false
+	}
+	post, ok := h.visiblePost(r, comment.PostID)
+	if !ok || !comment.VisibleTo(GetCurrentUser(r)) {
+		return nil, nil, false
+	}
+	return comment, post, true
+}
+
+// writeReactors renvoie une page de la liste des utilisateurs ayant posé la
+// réaction demandée (paramètre d'URL page)
+func (h *ReactionHandler) writeReactors(w http.ResponseWriter, r *http.Request, target models.ReactionTarget, targetID int64) {
+	reaction, known := h.ReactionStore.Registry.Get(mux.Vars(r)["type"])
+	if !known {
+		http.Error(w, "Réaction invalide", http.StatusBadRequest)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	reactors, err := h.ReactionStore.Reactors(target, targetID, reaction.Key, models.PaginationParams{Page: page, PerPage: reactorsPerPage})
+	if err != nil {
+		log.Printf("Erreur lors de la récupération des réactions: %v", err)
+		http.Error(w, "Erreur lors de la récupération des réactions", http.StatusInternalServerError)
+		return
+	}
+
+	// Les utilisateurs anonymes ne sont pas listés : ils ne comptent pas dans la pagination
+	listed := reactors.Total - reactors.Anonymous
+	users := reactors.Reactors
+	if users == nil {
+		users = []*models.Reactor{}
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"type":       reaction,
+		"users":      users,
+		"total":      reactors.Total,
+		"anonymous":  reactors.Anonymous,
+		"page":       page,
+		"totalPages": (listed + reactorsPerPage - 1) / reactorsPerPage,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"forum/database"
+	"forum/models"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// newTestDB crée une base temporaire avec toutes les migrations appliquées
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "forum.db")+"?_foreign_keys=on&_timeout=5000")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// newTestUser enregistre un utilisateur dans la base de test
+func newTestUser(t *testing.T, db *sql.DB, username string) *models.User {
+	t.Helper()
+	user := &models.User{
+		UUID:     "uuid-" + username,
+		Username: username,
+		Email:    username + "@exemple.fr",
+		Password: "hash",
+		Role:     models.RoleUser,
+	}
+	if err := models.NewUserStore(db).Create(user); err != nil {
+		t.Fatalf("failed to create user %s: %v", username, err)
+	}
+	return user
+}
+
+// newTestPost enregistre un post de l'utilisateur avec le statut donné
+func newTestPost(t *testing.T, db *sql.DB, author *models.User, status models.PostStatus) *models.Post {
+	t.Helper()
+	post := &models.Post{UserID: author.ID, Title: "Canaux", Content: "Question", Status: status}
+	if err := models.NewPostStore(db).Create(post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	return post
+}
+
+// newTestComment enregistre un commentaire de l'utilisateur avec le statut donné
+func newTestComment(t *testing.T, db *sql.DB, post *models.Post, author *models.User, status models.PostStatus) *models.Comment {
+	t.Helper()
+	comment := &models.Comment{PostID: post.ID, UserID: author.ID, Content: "Réponse", Status: status}
+	if err := models.NewCommentStore(db).Create(comment); err != nil {
+		t.Fatalf("failed to create comment: %v", err)
+	}
+	return comment
+}
+
+// newTestRequest prépare une requête authentifiée, avec les variables d'URL de mux
+func newTestRequest(method, target string, user *models.User, vars map[string]string) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	if user != nil {
+		r = r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+	}
+	return mux.SetURLVars(r, vars)
+}
+
+func newTestReactionHandler(db *sql.DB) *ReactionHandler {
+	return NewReactionHandler(
+		models.NewReactionStore(db, models.NewReactionRegistry(models.DefaultReactionTypes)),
+		models.NewPostStore(db),
+		models.NewCommentStore(db),
+		models.NewActivityStore(db),
+	)
+}
+
+func TestReactToPostVisibility(t *testing.T) {
+	db := newTestDB(t)
+	h := newTestReactionHandler(db)
+	author := newTestUser(t, db, "alice")
+	reader := newTestUser(t, db, "bob")
+
+	tests := []struct {
+		name       string
+		status     models.PostStatus
+		user       *models.User
+		wantStatus int
+	}{
+		{"post public", models.StatusApproved, reader, http.StatusOK},
+		{"post signalé", models.PostStatusReported, reader, http.StatusOK},
+		{"post en attente", models.StatusPending, reader, http.StatusNotFound},
+		{"post en attente, par son auteur", models.StatusPending, author, http.StatusOK},
+		{"post masqué", models.PostStatusHidden, reader, http.StatusNotFound},
+		{"post rejeté", models.StatusRejected, reader, http.StatusNotFound},
+		{"post à la corbeille", models.PostStatusDeleted, reader, http.StatusNotFound},
+		{"post à la corbeille, par son auteur", models.PostStatusDeleted, author, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(t, db, author, tt.status)
+			id := strconv.FormatInt(post.ID, 10)
+
+			w := httptest.NewRecorder()
+			h.ReactToPost(w, newTestRequest(http.MethodPost, "/api/post/"+id+"/like", tt.user, map[string]string{"id": id, "action": "like"}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			counts, err := h.ReactionStore.Counts(models.PostReactions, post.ID)
+			if err != nil {
+				t.Fatalf("Counts() failed: %v", err)
+			}
+			wantLikes := 0
+			if tt.wantStatus == http.StatusOK {
+				wantLikes = 1
+			}
+			if counts.Likes() != wantLikes {
+				t.Errorf("likes = %d, want %d", counts.Likes(), wantLikes)
+			}
+		})
+	}
+}
+
+func TestReactToCommentVisibility(t *testing.T) {
+	db := newTestDB(t)
+	h := newTestReactionHandler(db)
+	author := newTestUser(t, db, "alice")
+	reader := newTestUser(t, db, "bob")
+
+	tests := []struct {
+		name          string
+		postStatus    models.PostStatus
+		commentStatus models.PostStatus
+		byAuthor      bool
+		wantStatus    int
+	}{
+		{"commentaire public", models.StatusApproved, models.StatusApproved, false, http.StatusOK},
+		{"commentaire supprimé", models.StatusApproved, models.PostStatusDeleted, false, http.StatusNotFound},
+		{"commentaire masqué", models.StatusApproved, models.PostStatusHidden, false, http.StatusNotFound},
+		{"commentaire en attente", models.StatusApproved, models.StatusPending, false, http.StatusNotFound},
+		{"son commentaire en attente", models.StatusApproved, models.StatusPending, true, http.StatusOK},
+		{"son commentaire masqué", models.StatusApproved, models.PostStatusHidden, true, http.StatusNotFound},
+		{"post en attente", models.StatusPending, models.StatusApproved, false, http.StatusNotFound},
+		{"post masqué", models.PostStatusHidden, models.StatusApproved, false, http.StatusNotFound},
+		{"post à la corbeille", models.PostStatusDeleted, models.StatusApproved, false, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(t, db, author, tt.postStatus)
+			user := reader
+			if tt.byAuthor {
+				user = author
+			}
+			comment := newTestComment(t, db, post, author, tt.commentStatus)
+			id := strconv.FormatInt(comment.ID, 10)
+
+			w := httptest.NewRecorder()
+			h.ReactToComment(w, newTestRequest(http.MethodPost, "/api/comment/"+id+"/like", user, map[string]string{"id": id, "action": "like"}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			// Un refus ne doit pas compter la réaction
+			counts, err := h.ReactionStore.Counts(models.CommentReactions, comment.ID)
+			if err != nil {
+				t.Fatalf("Counts() failed: %v", err)
+			}
+			if tt.wantStatus != http.StatusOK && counts.Likes() != 0 {
+				t.Errorf("likes = %d on a refused reaction", counts.Likes())
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"forum/models"
+
+	"github.com/gorilla/mux"
+)
+
+// RevisionHandler affiche l'historique des posts et permet aux modérateurs de le restaurer
+type RevisionHandler struct {
+	PostStore     *models.PostStore
+	RevisionStore *models.RevisionStore
+	UserStore     *models.UserStore
+	ActivityStore *models.ActivityStore
+}
+
+func NewRevisionHandler(postStore *models.PostStore, revisionStore *models.RevisionStore, userStore *models.UserStore, activityStore *models.ActivityStore) *RevisionHandler {
+	return &RevisionHandler{
+		PostStore:     postStore,
+		RevisionStore: revisionStore,
+		UserStore:     userStore,
+		ActivityStore: activityStore,
+	}
+}
+
+func RegisterRevisionRoutes(r *mux.Router, h *RevisionHandler) {
+	r.HandleFunc("/post/{id:[0-9]+}/history", h.ShowHistory).Methods("GET")
+
+	rollback := RequireRole(models.RoleModerator)(http.HandlerFunc(h.Rollback))
+	r.Handle("/moderation/posts/{id:[0-9]+}/revisions/{revision:[0-9]+}/rollback", rollback).Methods("POST")
+}
+
+// revisionEntry est une version affichée dans l'historique, avec son différentiel
+// par rapport à la version précédente
+type revisionEntry struct {
+	*models.PostRevision
+	Author        *models.User
+	PreviousTitle string
+	Diff          []models.DiffLine
+	IsCurrent     bool
+}
+
+// ShowHistory affiche les versions successives d'un post
+func (h *RevisionHandler) ShowHistory(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil || (!post.Status.IsPublic() && !canEditPost(r, post)) {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+
+	revisions, err := h.RevisionStore.GetByPostID(postID)
+	if err != nil {
+		http.Error(w, "Erreur lors de la récupération de l'historique", http.StatusInternalServerError)
+		return
+	}
+
+	// Les versions sont affichées de la plus récente à la plus ancienne
+	authors := make(map[int64]*models.User)
+	entries := make([]revisionEntry, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		rev := revisions[i]
+		if _, exists := authors[rev.UserID]; !exists {
+			if user, err := h.UserStore.GetByID(rev.UserID); err == nil {
+				authors[rev.UserID] = user
+			}
+		}
+
+		entry := revisionEntry{
+			PostRevision: rev,
+			Author:       authors[rev.UserID],
+			IsCurrent:    i == len(revisions)-1,
+		}
+		if i > 0 {
+			previous := revisions[i-1]
+			entry.Diff = models.DiffLines(previous.Content, rev.Content)
+			if previous.Title != rev.Title {
+				entry.PreviousTitle = previous.Title
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	data := map[string]interface{}{
+		"Post":      post,
+		"Revisions": entries,
+	}
+	if user := GetCurrentUser(r); user != nil {
+		data["User"] = user
+		data["IsAuthenticated"] = true
+	}
+
+	RenderTemplate(w, r, "post_history.html", data)
+}
+
+// Rollback restaure une version précédente d'un post, enregistrée comme une nouvelle version
+func (h *RevisionHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de post invalide", http.StatusBadRequest)
+		return
+	}
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		http.Error(w, "Version invalide", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.PostStore.GetByID(postID)
+	if err != nil {
+		http.Error(w, "Post non trouvé", http.StatusNotFound)
+		return
+	}
+	if post.Status == models.PostStatusDeleted {
+		http.Error(w, "Ce post est dans la corbeille", http.StatusConflict)
+		return
+	}
+
+	rev, err := h.RevisionStore.GetRevision(postID, revision)
+	if err != nil {
+		http.Error(w, "Version non trouvée", http.StatusNotFound)
+		return
+	}
+
+	if rev.Title == post.Title && rev.Content == post.Content {
+		http.Redirect(w, r, fmt.Sprintf("/post/%d/history", postID), http.StatusSeeOther)
+		return
+	}
+
+	moderator := GetCurrentUser(r)
+	before := *post
+	post.Title = rev.Title
+	post.Content = rev.Content
+
+	if err := h.PostStore.UpdateWithRevision(&before, post, moderator.ID); err != nil {
+		log.Printf("Erreur lors de la restauration du post %d: %v", postID, err)
+		http.Error(w, "Erreur lors de la restauration du post", http.StatusInternalServerError)
+		return
+	}
+
+	if post.UserID != moderator.ID {
+		activity := &models.Activity{
+			UserID:      moderator.ID,
+			RecipientID: post.UserID,
+			Type:        models.ActivityModeration,
+			TargetID:    post.ID,
+			CreatedAt:   time.Now(),
+			Content:     fmt.Sprintf("a restauré la version %d de votre post", revision),
+			IsRead:      false,
+		}
+		if err := h.ActivityStore.Create(activity); err != nil {
+			log.Printf("Erreur lors de la notification de l'utilisateur %d: %v", post.UserID, err)
+		}
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/post/%d", postID), http.StatusSeeOther)
+}
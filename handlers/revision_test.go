@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"forum/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestRollbackPostStatus(t *testing.T) {
+	db := newTestDB(t)
+	postStore := models.NewPostStore(db)
+	h := NewRevisionHandler(postStore, models.NewRevisionStore(db), models.NewUserStore(db), models.NewActivityStore(db))
+	author := newTestUser(t, db, "alice")
+	moderator := newTestUser(t, db, "carole")
+	moderator.Role = models.RoleModerator
+
+	tests := []struct {
+		name        string
+		status      models.PostStatus
+		wantStatus  int
+		wantContent string
+	}{
+		{"post public", models.StatusApproved, http.StatusSeeOther, "Question"},
+		{"post masqué", models.PostStatusHidden, http.StatusSeeOther, "Question"},
+		{"post à la corbeille", models.PostStatusDeleted, http.StatusConflict, "Question modifiée"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(t, db, author, tt.status)
+			before := *post
+			post.Content = "Question modifiée"
+			if err := postStore.UpdateWithRevision(&before, post, author.ID); err != nil {
+				t.Fatalf("failed to edit post: %v", err)
+			}
+
+			id := strconv.FormatInt(post.ID, 10)
+			w := httptest.NewRecorder()
+			r := newTestRequest(http.MethodPost, "/moderation/posts/"+id+"/revisions/1/rollback", moderator,
+				map[string]string{"id": id, "revision": "1"})
+			h.Rollback(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Rollback() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			stored, err := postStore.GetByID(post.ID)
+			if err != nil {
+				t.Fatalf("failed to read post: %v", err)
+			}
+			if stored.Content != tt.wantContent {
+				t.Errorf("content after rollback = %q, want %q", stored.Content, tt.wantContent)
+			}
+		})
+	}
+}
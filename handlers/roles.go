@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"forum/models"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RequireRole restreint l'accès aux utilisateurs possédant au moins le rôle demandé.
+// Les visiteurs non connectés sont redirigés vers la page de connexion.
+func RequireRole(role models.UserRole) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := GetCurrentUser(r)
+			if user == nil {
+				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				return
+			}
+			if !user.HasRole(role) {
+				log.Printf("Accès refusé à %s pour l'utilisateur %d (rôle %s, requis %s)", r.URL.Path, user.ID, user.Role, role)
+				http.Error(w, "Accès refusé", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// canEditPost indique si l'utilisateur courant peut modifier ou supprimer le post
+func canEditPost(r *http.Request, post *models.Post) bool {
+	user := GetCurrentUser(r)
+	return user != nil && post.CanEdit(user.ID, user.Role)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags liste les balises conservées par SanitizeHTML et leurs attributs autorisés
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"blockquote": nil,
+	"br":         nil,
+	"code":       {"class"},
+	"del":        nil,
+	"em":         nil,
+	"h1":         {"id"},
+	"h2":         {"id"},
+	"h3":         {"id"},
+	"h4":         {"id"},
+	"h5":         {"id"},
+	"h6":         {"id"},
+	"hr":         nil,
+	"img":        {"src", "alt", "title"},
+	"kbd":        nil,
+	"li":         nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"s":          nil,
+	"strong":     nil,
+	"sub":        nil,
+	"span":       {"class"},
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"align"},
+	"th":         {"align"},
+	"thead":      nil,
+	"tr":         nil,
+	"ul":         nil,
+}
+
+// droppedTags sont supprimées avec tout leur contenu
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "textarea": true, "title": true, "template": true, "svg": true, "math": true,
+}
+
+// voidTags n'ont pas de balise fermante
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// allowedSchemes sont les protocoles acceptés dans href et src (les URL relatives sont acceptées)
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// SanitizeHTML ne conserve que les balises et attributs de la liste blanche.
+// Le texte est ré-échappé et les balises restées ouvertes sont refermées,
+// afin qu'un contenu utilisateur ne puisse ni injecter de script ni casser la mise en page.
+func SanitizeHTML(input string) string {
+	var out strings.Builder
+	var open []string
+	skipDepth := 0
+	skipTag := ""
+
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				return ""
+			}
+			break
+		}
+
+		token := tokenizer.Token()
+
+		// Contenu d'une balise supprimée : on attend sa fermeture
+		if skipDepth > 0 {
+			switch {
+			case tokenType == html.StartTagToken && token.Data == skipTag:
+				skipDepth++
+			case tokenType == html.EndTagToken && token.Data == skipTag:
+				skipDepth--
+			}
+			continue
+		}
+
+		switch tokenType {
+		case html.TextToken:
+			out.WriteString(html.EscapeString(token.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[token.Data] {
+				if tokenType == html.StartTagToken {
+					skipDepth, skipTag = 1, token.Data
+				}
+				continue
+			}
+			attrs, allowed := allowedTags[token.Data]
+			if !allowed {
+				continue
+			}
+			writeStartTag(&out, token, attrs)
+			if !voidTags[token.Data] && tokenType == html.StartTagToken {
+				open = append(open, token.Data)
+			}
+
+		case html.EndTagToken:
+			// Une balise fermante n'est conservée que si elle ferme une balise ouverte par le contenu
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+
+	return out.String()
+}
+
+// writeStartTag écrit une balise ouvrante en ne gardant que les attributs autorisés
+func writeStartTag(out *strings.Builder, token html.Token, allowedAttrs []string) {
+	out.WriteString("<" + token.Data)
+	for _, attr := range token.Attr {
+		if attr.Namespace != "" || !containsString(allowedAttrs, attr.Key) {
+			continue
+		}
+		if (attr.Key == "href" || attr.Key == "src") && !isSafeURL(attr.Val) {
+			continue
+		}
+		if attr.Key == "class" && !allowedClass(token.Data, attr.Val) {
+			continue
+		}
+		out.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if token.Data == "a" {
+		// Les liens des utilisateurs ne transmettent ni référence ni autorité
+		out.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	out.WriteString(">")
+}
+
+// allowedClass ne conserve que les classes produites par le rendu Markdown :
+// langage des blocs de code et formules mathématiques
+func allowedClass(tag, class string) bool {
+	switch tag {
+	case "code":
+		return strings.HasPrefix(class, "language-")
+	case "span":
+		return class == "math inline" || class == "math display"
+	}
+	return false
+}
+
+// isSafeURL accepte les URL relatives et celles dont le protocole est autorisé
+func isSafeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || allowedSchemes[strings.ToLower(u.Scheme)]
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"balises autorisées", `<p>Un <strong>mot</strong> et <em>un autre</em></p>`, `<p>Un <strong>mot</strong> et <em>un autre</em></p>`},
+		{"texte ré-échappé", `a &lt; b &amp; "c"`, `a &lt; b &amp; &#34;c&#34;`},
+		{"script supprimé avec son contenu", `<script>alert(1)</script><p>ok</p>`, `<p>ok</p>`},
+		{"balises supprimées imbriquées", `<svg><svg><a>x</a></svg><p>dedans</p></svg><p>ok</p>`, `<p>ok</p>`},
+		{"style dans un paragraphe", `<p>a<style>p{color:red}</style>b</p>`, `<p>ab</p>`},
+		{"balise inconnue retirée, contenu gardé", `<div><b>gras</b></div>`, `gras`},
+		{"lien javascript", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"lien JaVaScRiPt", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"lien javascript avec espaces", `<a href="  javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"lien javascript en entités", `<a href="&#106;avascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"lien avec tabulation en entité", `<a href="java&#x09;script:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"lien https", `<a href="https://example.com" onclick="x()">x</a>`, `<a href="https://example.com" rel="nofollow noopener noreferrer">x</a>`},
+		{"image avec onerror", `<img src="x.png" onerror="alert(1)">`, `<img src="x.png">`},
+		{"image data", `<img src="data:text/html;base64,PHNjcmlwdD4=" alt="a">`, `<img alt="a">`},
+		{"fermante orpheline", `</p><em>a</strong>b</em>`, `<em>ab</em>`},
+		{"fermante d'une balise parente", `<strong><em>a</strong>b`, `<strong><em>a</em></strong>b`},
+		{"balises restées ouvertes", `<ul><li>a`, `<ul><li>a</li></ul>`},
+		{"classe de langage", `<code class="language-go">x</code>`, `<code class="language-go">x</code>`},
+		{"classe hors liste sur code", `<code class="evil">x</code>`, `<code>x</code>`},
+		{"classe mathématique", `<span class="math inline">x</span>`, `<span class="math inline">x</span>`},
+		{"classe mathématique complétée", `<span class="math inline evil">x</span>`, `<span>x</span>`},
+		{"classe sur une balise sans classe", `<p class="code-block">x</p>`, `<p>x</p>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeHTML(tt.input); got != tt.want {
+				t.Errorf("SanitizeHTML(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"HTTP://example.com", true},
+		{"mailto:alice@example.com", true},
+		{"/posts/1", true},
+		{"#section", true},
+		{"javascript:alert(1)", false},
+		{"JaVaScRiPt:alert(1)", false},
+		{" \tjavascript:alert(1)", false},
+		{"java\tscript:alert(1)", false},
+		{"vbscript:msgbox(1)", false},
+		{"data:text/html,<script>", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := isSafeURL(tt.url); got != tt.want {
+				t.Errorf("isSafeURL(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowedClass(t *testing.T) {
+	tests := []struct {
+		tag   string
+		class string
+		want  bool
+	}{
+		{"code", "language-go", true},
+		{"code", "chroma", false},
+		{"span", "math inline", true},
+		{"span", "math display", true},
+		{"span", "math", false},
+		{"span", "code-language", false},
+		{"p", "math inline", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag+" "+tt.class, func(t *testing.T) {
+			if got := allowedClass(tt.tag, tt.class); got != tt.want {
+				t.Errorf("allowedClass(%q, %q) = %v, want %v", tt.tag, tt.class, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownCodeBlockMarker(t *testing.T) {
+	// Un marqueur de bloc de code écrit par l'utilisateur reste du texte :
+	// seul le marqueur émis pour ce rendu, au nonce aléatoire, est remplacé
+	source := "codeblock-0000000000000000-0 <script>alert(1)</script>\n\n```go\nfmt.Println(1)\n```\n"
+	got := string(RenderMarkdown(source))
+
+	if !strings.Contains(got, "codeblock-0000000000000000-0") {
+		t.Errorf("RenderMarkdown() dropped the literal marker: %q", got)
+	}
+	if strings.Contains(got, "<script>") {
+		t.Errorf("RenderMarkdown() kept a script tag: %q", got)
+	}
+	if n := strings.Count(got, `<div class="code-block">`); n != 1 {
+		t.Errorf("RenderMarkdown() rendered %d code blocks, want 1: %q", n, got)
+	}
+	if n := strings.Count(got, "codeblock-"); n != 1 {
+		t.Errorf("RenderMarkdown() left a generated marker: %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"forum/models"
+)
+
+// Format des dates des champs de recherche (<input type="date">)
+const searchDateLayout = "2006-01-02"
+
+// statusOption est un statut proposé dans le formulaire de recherche
+type statusOption struct {
+	Value models.PostStatus
+	Label string
+}
+
+// Statuts proposés aux modérateurs dans la recherche avancée
+var searchStatuses = []statusOption{
+	{models.StatusApproved, "Publiés"},
+	{models.StatusPending, "En attente"},
+	{models.PostStatusReported, "Signalés"},
+	{models.PostStatusHidden, "Masqués"},
+	{models.StatusRejected, "Rejetés"},
+	{models.PostStatusDeleted, "Dans la corbeille"},
+}
+
+// SearchPage affiche la recherche avancée. Tous les critères sont passés en
+// paramètres GET : l'URL d'une recherche peut être partagée telle quelle.
+func (h *PostHandler) SearchPage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	currentUser := GetCurrentUser(r)
+	isModerator := currentUser != nil && currentUser.IsModerator()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage := 10
+
+	filter := models.PostFilter{
+		Statuses: models.PublicPostStatuses,
+	}
+	filter.Pagination.Page = page
+	filter.Pagination.PerPage = perPage
+
+	// Critères retenus, réutilisés pour les liens de pagination
+	params := url.Values{}
+
+	filter.Search = strings.TrimSpace(query.Get("q"))
+	if filter.Search != "" {
+		params.Set("q", filter.Search)
+	}
+
+	authorName := strings.TrimSpace(query.Get("author"))
+	authorNotFound := false
+	if authorName != "" {
+		params.Set("author", authorName)
+		if author, err := h.UserStore.GetByUsername(authorName); err == nil {
+			filter.UserID = author.ID
+		} else {
+			authorNotFound = true
+		}
+	}
+
+	dateFrom := query.Get("from")
+	if from, err := time.ParseInLocation(searchDateLayout, dateFrom, time.Local); err == nil {
+		filter.DateFrom = from
+		params.Set("from", dateFrom)
+	} else {
+		dateFrom = ""
+	}
+
+	dateTo := query.Get("to")
+	if to, err := time.ParseInLocation(searchDateLayout, dateTo, time.Local); err == nil {
+		// La date de fin est incluse : jusqu'à la fin de la journée
+		filter.DateTo = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		params.Set("to", dateTo)
+	} else {
+		dateTo = ""
+	}
+
+	tags := parseTagSelection(query, &filter)
+	tags.encode(params)
+
+	switch postType := models.PostType(query.Get("type")); postType {
+	case models.PostTypeDiscussion, models.PostTypeQuestion:
+		filter.Type = postType
+		params.Set("type", string(postType))
+	}
+
+	if query.Get("has_image") == "1" {
+		filter.HasImage = true
+		params.Set("has_image", "1")
+	}
+
+	if query.Get("answered") == "1" {
+		filter.HasAcceptedAnswer = true
+		params.Set("answered", "1")
+	}
+
+	if minLikes, err := strconv.Atoi(query.Get("min_likes")); err == nil && minLikes > 0 {
+		filter.MinLikes = minLikes
+		params.Set("min_likes", strconv.Itoa(minLikes))
+	}
+
+	if minComments, err := strconv.Atoi(query.Get("min_comments")); err == nil && minComments > 0 {
+		filter.MinComments = minComments
+		params.Set("min_comments", strconv.Itoa(minComments))
+	}
+
+	// Les modérateurs peuvent chercher parmi les contenus non publics
+	status := models.PostStatus(query.Get("status"))
+	if isModerator && status != "" {
+		for _, allowed := range searchStatuses {
+			if status == allowed.Value {
+				filter.Status = status
+				filter.Statuses = nil
+				params.Set("status", string(status))
+				break
+			}
+		}
+	}
+
+	sortBy := applySort(&filter, query.Get("sort"))
+	params.Set("sort", sortBy)
+
+	var posts []*models.Post
+	totalPosts := 0
+	if !authorNotFound {
+		posts, err = h.PostStore.FilterPosts(filter)
+		if err != nil {
+			http.Error(w, "Erreur lors de la recherche", http.StatusInternalServerError)
+			return
+		}
+		totalPosts, _ = h.PostStore.CountPosts(filter)
+	}
+	totalPages := (totalPosts + perPage - 1) / perPage
+
+	authors, postTags, commentCounts := h.postListData(posts)
+	allTags, _ := h.TagStore.GetAllTags()
+
+	data := map[string]interface{}{
+		"Posts":             posts,
+		"Authors":           authors,
+		"PostTags":          postTags,
+		"CommentCounts":     commentCounts,
+		"AllTags":           allTags,
+		"TotalPosts":        totalPosts,
+		"CurrentPage":       page,
+		"TotalPages":        totalPages,
+		"PaginationBaseURL": "/search?" + params.Encode(),
+		"Query":             filter.Search,
+		"Author":            authorName,
+		"AuthorNotFound":    authorNotFound,
+		"DateFrom":          dateFrom,
+		"DateTo":            dateTo,
+		"IncludedTags":      tags.Included,
+		"ExcludedTags":      tags.Excluded,
+		"TagMatch":          tags.Match,
+		"Type":              string(filter.Type),
+		"HasImage":          filter.HasImage,
+		"Answered":          filter.HasAcceptedAnswer,
+		"MinLikes":          filter.MinLikes,
+		"MinComments":       filter.MinComments,
+		"Status":            string(filter.Status),
+		"SortBy":            sortBy,
+	}
+
+	if currentUser != nil {
+		data["CurrentUser"] = currentUser
+		data["IsAuthenticated"] = true
+	}
+	if isModerator {
+		data["IsModerator"] = true
+		data["Statuses"] = searchStatuses
+	}
+
+	RenderTemplate(w, r, "search.html", data)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"forum/models"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Nom du cookie contenant le jeton de session
+const sessionCookieName = "session_token"
+
+type contextKey string
+
+const (
+	userContextKey    contextKey = "user"
+	sessionContextKey contextKey = "session"
+)
+
+// SessionMiddleware résout l'utilisateur courant à partir du cookie de session
+// et le place dans le contexte de la requête pour tous les handlers.
+func SessionMiddleware(sessionStore *models.SessionStore, userStore *models.UserStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie(sessionCookieName)
+			if err != nil || cookie.Value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			session, err := sessionStore.GetByToken(cookie.Value)
+			if err != nil {
+				// Session inconnue, expirée ou révoquée : on efface le cookie
+				clearSessionCookie(w)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, err := userStore.GetByID(session.UserID)
+			if err != nil {
+				log.Printf("Session %d liée à un utilisateur introuvable: %v", session.ID, err)
+				sessionStore.DeleteByToken(cookie.Value)
+				clearSessionCookie(w)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Un compte suspendu perd immédiatement ses sessions
+			if user.IsBanned() {
+				log.Printf("Session %d révoquée: utilisateur %d suspendu", session.ID, user.ID)
+				sessionStore.DeleteByUserID(user.ID)
+				clearSessionCookie(w)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Renouvellement glissant de la session
+			renewed, err := sessionStore.Touch(session)
+			if err != nil {
+				log.Printf("Erreur lors du renouvellement de la session %d: %v", session.ID, err)
+			} else if renewed {
+				setSessionCookie(w, r, cookie.Value, session.ExpiresAt)
+			}
+
+			ctx := context.WithValue(r.Context(), userContextKey, user)
+			ctx = context.WithValue(ctx, sessionContextKey, session)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetCurrentUser retourne l'utilisateur authentifié de la requête, ou nil
+func GetCurrentUser(r *http.Request) *models.User {
+	user, _ := r.Context().Value(userContextKey).(*models.User)
+	return user
+}
+
+// GetCurrentSession retourne la session de la requête, ou nil
+func GetCurrentSession(r *http.Request) *models.Session {
+	session, _ := r.Context().Value(sessionContextKey).(*models.Session)
+	return session
+}
+
+// GetUserIDFromRequest retourne l'ID de l'utilisateur authentifié, ou 0
+func GetUserIDFromRequest(r *http.Request) int64 {
+	if user := GetCurrentUser(r); user != nil {
+		return user.ID
+	}
+	return 0
+}
+
+// startSession crée une session pour l'utilisateur et pose le cookie
+func startSession(w http.ResponseWriter, r *http.Request, sessionStore *models.SessionStore, userID int64) error {
+	session, token, err := sessionStore.Create(userID, clientIP(r), r.UserAgent())
+	if err != nil {
+		return err
+	}
+	setSessionCookie(w, r, token, session.ExpiresAt)
+	return nil
+}
+
+func setSessionCookie(w http.ResponseWriter, r *http.Request, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Expires:  expires,
+		HttpOnly: true,
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+// clientIP retourne l'adresse IP du client sans le port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+
+	"forum/models"
+)
+
+// tagSelection regroupe les tags inclus et exclus d'une liste de posts.
+// Paramètres d'URL : tag (inclus, répétable), xtag (exclu, répétable) et
+// match (all ou any, pour combiner les tags inclus).
+type tagSelection struct {
+	Included map[int64]bool
+	Excluded map[int64]bool
+	Match    string
+}
+
+// parseTagSelection lit les tags demandés dans l'URL et les applique au filtre
+func parseTagSelection(query url.Values, filter *models.PostFilter) tagSelection {
+	selection := tagSelection{
+		Included: make(map[int64]bool),
+		Excluded: make(map[int64]bool),
+		Match:    models.TagMatchAll,
+	}
+	if query.Get("match") == models.TagMatchAny {
+		selection.Match = models.TagMatchAny
+	}
+
+	for _, value := range query["tag"] {
+		if tagID, err := strconv.ParseInt(value, 10, 64); err == nil {
+			selection.Included[tagID] = true
+		}
+	}
+	for _, value := range query["xtag"] {
+		// Un tag à la fois inclus et exclu reste inclus
+		if tagID, err := strconv.ParseInt(value, 10, 64); err == nil && !selection.Included[tagID] {
+			selection.Excluded[tagID] = true
+		}
+	}
+
+	filter.Tags = sortedIDs(selection.Included)
+	filter.ExcludeTags = sortedIDs(selection.Excluded)
+	filter.TagMatch = selection.Match
+	return selection
+}
+
+// IsEmpty indique qu'aucun tag n'est inclus ni exclu
+func (s tagSelection) IsEmpty() bool {
+	return len(s.Included) == 0 && len(s.Excluded) == 0
+}
+
+// encode ajoute la sélection aux paramètres d'URL
+func (s tagSelection) encode(params url.Values) {
+	for _, tagID := range sortedIDs(s.Included) {
+		params.Add("tag", strconv.FormatInt(tagID, 10))
+	}
+	for _, tagID := range sortedIDs(s.Excluded) {
+		params.Add("xtag", strconv.FormatInt(tagID, 10))
+	}
+	if len(s.Included) > 1 {
+		params.Set("match", s.Match)
+	}
+}
+
+// with retourne une copie de la sélection où le tag a l'état demandé :
+// inclus, exclu, ou ni l'un ni l'autre
+func (s tagSelection) with(tagID int64, include, exclude bool) tagSelection {
+	next := tagSelection{
+		Included: make(map[int64]bool, len(s.Included)+1),
+		Excluded: make(map[int64]bool, len(s.Excluded)+1),
+		Match:    s.Match,
+	}
+	for id := range s.Included {
+		next.Included[id] = true
+	}
+	for id := range s.Excluded {
+		next.Excluded[id] = true
+	}
+	delete(next.Included, tagID)
+	delete(next.Excluded, tagID)
+	if include {
+		next.Included[tagID] = true
+	}
+	if exclude {
+		next.Excluded[tagID] = true
+	}
+	return next
+}
+
+// url construit l'adresse de la page avec cette sélection ; base contient
+// les autres paramètres à conserver (recherche, tri...)
+func (s tagSelection) url(path string, base url.Values) string {
+	params := url.Values{}
+	for key, values := range base {
+		params[key] = append([]string(nil), values...)
+	}
+	s.encode(params)
+	if encoded := params.Encode(); encoded != "" {
+		return path + "?" + encoded
+	}
+	return path
+}
+
+// tagToggle est un tag du nuage de tags, avec les liens qui changent son état
+type tagToggle struct {
+	Tag        *models.Tag
+	Included   bool
+	Excluded   bool
+	ToggleURL  string // Inclut le tag, ou le retire s'il l'est déjà
+	ExcludeURL string // Exclut le tag, ou lève l'exclusion
+}
+
+// toggles construit les liens du nuage de tags à partir de la sélection courante
+func (s tagSelection) toggles(tags []*models.Tag, path string, base url.Values) []tagToggle {
+	entries := make([]tagToggle, 0, len(tags))
+	for _, tag := range tags {
+		entry := tagToggle{
+			Tag:      tag,
+			Included: s.Included[tag.ID],
+			Excluded: s.Excluded[tag.ID],
+		}
+		entry.ToggleURL = s.with(tag.ID, !entry.Included, false).url(path, base)
+		entry.ExcludeURL = s.with(tag.ID, false, !entry.Excluded).url(path, base)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// sortedIDs retourne les identifiants d'un ensemble, triés pour des URL stables
+func sortedIDs(set map[int64]bool) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -77,19 +77,19 @@ func (h *TagHandler) ShowTagPosts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Récupérer le nombre de commentaires pour chaque post
+	// Récupérer le nombre de commentaires publics pour chaque post
 	commentCounts := make(map[int64]int)
 	for _, post := range posts {
-		comments, err := h.CommentStore.GetCommentsByPostID(post.ID)
+		count, err := h.PostStore.GetCommentCount(post.ID)
 		if err == nil {
-			commentCounts[post.ID] = len(comments)
+			commentCounts[post.ID] = count
 		} else {
 			commentCounts[post.ID] = 0
 		}
 	}
 
 	// Vérifier si l'utilisateur est authentifié
-	userID := getUserIDFromCookie(r)
+	userID := GetUserIDFromRequest(r)
 	isAuthenticated := userID > 0
 
 	// Préparation des données pour le template
@@ -103,5 +103,5 @@ func (h *TagHandler) ShowTagPosts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Rendre le template
-	RenderTemplate(w, "tag_view.html", data)
+	RenderTemplate(w, r, "tag_view.html", data)
 }
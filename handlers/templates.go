@@ -5,6 +5,9 @@ import (
 	"html/template"
 	"net/http"
 	"path/filepath"
+	"strings"
+
+	"forum/models"
 )
 
 var Templates *template.Template
@@ -35,6 +38,34 @@ func LoadTemplates(templateDir string) error {
 			}
 			return result
 		},
+		// Construit une map à partir de paires clé/valeur, pour les templates récursifs
+		"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+			if len(pairs)%2 != 0 {
+				return nil, fmt.Errorf("dict: nombre d'arguments impair")
+			}
+			m := make(map[string]interface{}, len(pairs)/2)
+			for i := 0; i < len(pairs); i += 2 {
+				key, ok := pairs[i].(string)
+				if !ok {
+					return nil, fmt.Errorf("dict: clé non textuelle %v", pairs[i])
+				}
+				m[key] = pairs[i+1]
+			}
+			return m, nil
+		},
+		// Rendu Markdown assaini des posts et commentaires
+		"markdown": RenderMarkdown,
+		// Extrait de recherche avec les termes trouvés surlignés
+		"snippet": func(text string) template.HTML {
+			escaped := template.HTMLEscapeString(text)
+			escaped = strings.ReplaceAll(escaped, models.SnippetMatchStart, "<mark>")
+			escaped = strings.ReplaceAll(escaped, models.SnippetMatchEnd, "</mark>")
+			return template.HTML(escaped)
+		},
+		// Champ caché à placer dans chaque formulaire POST
+		"csrfField": func(token string) template.HTML {
+			return template.HTML(`<input type="hidden" name="` + csrfFormField + `" value="` + template.HTMLEscapeString(token) + `">`)
+		},
 	}
 
 	templates := template.New("").Funcs(funcMap)
@@ -49,7 +80,7 @@ func LoadTemplates(templateDir string) error {
 	return nil
 }
 
-func RenderTemplate(w http.ResponseWriter, tmpl string, data interface{}) error {
+func RenderTemplate(w http.ResponseWriter, r *http.Request, tmpl string, data interface{}) error {
 	if Templates == nil {
 		return fmt.Errorf("templates not initialized")
 	}
@@ -67,5 +98,8 @@ func RenderTemplate(w http.ResponseWriter, tmpl string, data interface{}) error
 	// Always set the ContentTemplate
 	templateData["ContentTemplate"] = tmpl
 
+	// Jeton CSRF disponible dans tous les templates
+	templateData["CSRFToken"] = GetCSRFToken(r)
+
 	return Templates.ExecuteTemplate(w, "base.html", templateData)
 }
@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"forum/database"
@@ -18,6 +19,12 @@ func main() {
 	// Configuration des logs
 	log.SetOutput(os.Stdout)
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	os.Exit(runCommand(os.Args[1:]))
+}
+
+// runServe démarre le serveur web du forum
+func runServe() {
 	log.Println("Démarrage de l'application Forum...")
 
 	// Chargement des templates
@@ -29,7 +36,8 @@ func main() {
 	// Vérification des templates essentiels
 	requiredTemplates := []string{
 		"base.html", "auth.html", "post_forms.html", "post_view.html",
-		"profile.html", "index.html", "notifications.html",
+		"profile.html", "index.html", "notifications.html", "moderation.html", "post_history.html",
+		"search.html",
 	}
 
 	for _, tmpl := range requiredTemplates {
@@ -49,66 +57,111 @@ func main() {
 	if err := database.RunMigrations(db); err != nil {
 		log.Fatalf("Échec des migrations: %v", err)
 	}
+	if fts5, err := database.HasFTS5(db); err != nil || !fts5 {
+		log.Printf("Recherche plein texte indisponible (SQLite sans FTS5), repli sur une recherche simple")
+		models.FullTextSearch = false
+	}
+
+	// Initialisation des stores
+	userStore := models.NewUserStore(db)
+	sessionStore := models.NewSessionStore(db)
+	apiTokenStore := models.NewAPITokenStore(db)
+	postStore := models.NewPostStore(db)
+	tagStore := models.NewTagStore(db)
+	commentStore := models.NewCommentStore(db)
+	reactionStore := models.NewReactionStore(db, models.LoadReactionRegistry())
+	activityStore := models.NewActivityStore(db)
+	reportStore := models.NewReportStore(db)
+	revisionStore := models.NewRevisionStore(db)
+
+	// Configuration de la pré-modération
+	moderationPolicy := models.LoadModerationPolicy()
+	log.Printf("Pré-modération: %s (comptes de moins de %s)", moderationPolicy.Mode, moderationPolicy.NewAccountAge)
+
+	// Profondeur maximale des fils de discussion
+	if value := os.Getenv("COMMENT_MAX_DEPTH"); value != "" {
+		if depth, err := strconv.Atoi(value); err == nil && depth > 0 {
+			models.CommentMaxDepth = depth
+		} else {
+			log.Printf("COMMENT_MAX_DEPTH invalide %q, valeur par défaut utilisée", value)
+		}
+	}
+
+	// Durée de conservation des posts dans la corbeille
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		if days, err := strconv.Atoi(value); err == nil && days > 0 {
+			models.TrashRetention = time.Duration(days) * 24 * time.Hour
+		} else {
+			log.Printf("TRASH_RETENTION_DAYS invalide %q, valeur par défaut utilisée", value)
+		}
+	}
+	go runTrashPurge(postStore, time.Hour)
+
+	// Nettoyage des sessions expirées
+	if n, err := sessionStore.DeleteExpired(); err != nil {
+		log.Printf("Échec du nettoyage des sessions: %v", err)
+	} else if n > 0 {
+		log.Printf("%d session(s) expirée(s) supprimée(s)", n)
+	}
 
 	// Configuration du routeur
 	r := mux.NewRouter()
 	r.Use(loggingMiddleware)
+	r.Use(handlers.SessionMiddleware(sessionStore, userStore))
+	r.Use(handlers.APITokenMiddleware(apiTokenStore, userStore))
+	r.Use(handlers.CSRFMiddleware)
 
 	// Fichiers statiques
 	fs := http.FileServer(http.Dir("./static"))
 	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", fs))
 
-	// Initialisation des stores
-	userStore := models.NewUserStore(db)
-	postStore := models.NewPostStore(db)
-	tagStore := models.NewTagStore(db)
-	commentStore := models.NewCommentStore(db)
-	likeStore := models.NewLikeStore(db)
-	activityStore := models.NewActivityStore(db)
-
 	// Initialisation des handlers
-	likeHandler := handlers.NewLikeHandler(likeStore, postStore, commentStore)
-	postHandler := handlers.NewPostHandler(postStore, tagStore, commentStore, userStore, likeStore, activityStore)
+	reactionHandler := handlers.NewReactionHandler(reactionStore, postStore, commentStore, activityStore)
+	postHandler := handlers.NewPostHandler(postStore, tagStore, commentStore, userStore, reactionStore, activityStore, revisionStore, moderationPolicy)
 	tagHandler := handlers.NewTagHandler(tagStore, postStore, userStore, commentStore)
-	authHandler := handlers.NewAuthHandler(userStore)
-	profileHandler := handlers.NewProfileHandler(userStore, postStore)
+	authHandler := handlers.NewAuthHandler(userStore, sessionStore)
+	profileHandler := handlers.NewProfileHandler(userStore, postStore, sessionStore, apiTokenStore)
 	notificationHandler := handlers.NewNotificationHandler(activityStore, userStore, postStore, commentStore)
+	moderationHandler := handlers.NewModerationHandler(reportStore, postStore, commentStore, userStore, activityStore)
+	revisionHandler := handlers.NewRevisionHandler(postStore, revisionStore, userStore, activityStore)
+	apiHandler := handlers.NewAPIHandler(postStore, tagStore, commentStore, userStore, reactionStore, activityStore, revisionStore, moderationPolicy)
 
 	// Enregistrement des routes spécifiques à chaque domaine
-	handlers.RegisterCommentRoutes(r)
-	handlers.RegisterLikeRoutes(r, likeHandler)
+	handlers.RegisterCommentRoutes(r, moderationPolicy)
+	handlers.RegisterReactionRoutes(r, reactionHandler)
 	handlers.RegisterTagRoutes(r, tagHandler)
 	handlers.RegisterNotificationRoutes(r, notificationHandler)
+	handlers.RegisterModerationRoutes(r, moderationHandler)
+	handlers.RegisterRevisionRoutes(r, revisionHandler)
+	handlers.RegisterAPIRoutes(r, apiHandler)
 
 	// Routes d'authentification
 	r.HandleFunc("/login", authHandler.ShowLogin).Methods("GET")
 	r.HandleFunc("/login", authHandler.Login).Methods("POST")
 	r.HandleFunc("/register", authHandler.Register).Methods("POST")
-	r.HandleFunc("/logout", authHandler.Logout).Methods("GET")
+	r.HandleFunc("/logout", authHandler.Logout).Methods("POST")
 
 	// Routes pour les posts
 	r.HandleFunc("/", postHandler.HomePage).Methods("GET")
+	r.HandleFunc("/search", postHandler.SearchPage).Methods("GET")
 	r.HandleFunc("/post/{id}", postHandler.ViewPost).Methods("GET")
 	r.HandleFunc("/create-post", postHandler.NewPostPage).Methods("GET")
 	r.HandleFunc("/create-post", postHandler.CreatePost).Methods("POST")
 	r.HandleFunc("/edit-post/{id}", postHandler.EditPostPage).Methods("GET")
 	r.HandleFunc("/edit-post/{id}", postHandler.UpdatePost).Methods("POST")
 	r.HandleFunc("/delete-post/{id}", postHandler.DeletePost).Methods("POST")
+	r.HandleFunc("/restore-post/{id}", postHandler.RestorePost).Methods("POST")
+	r.HandleFunc("/preview", handlers.PreviewMarkdown).Methods("POST")
+	r.HandleFunc("/post/{id}/accept/{commentID:[0-9]+}", postHandler.AcceptAnswer).Methods("POST")
+	r.HandleFunc("/post/{id}/unaccept", postHandler.UnacceptAnswer).Methods("POST")
 
 	// Routes pour les profils
 	r.HandleFunc("/user/{id:[0-9]+}", profileHandler.ShowUserProfile).Methods("GET")
-	r.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
-		if _, err := r.Cookie("user_id"); err != nil {
-			http.Redirect(w, r, "/login", http.StatusSeeOther)
-			return
-		}
-		profileHandler.ShowProfile(w, r)
-	}).Methods("GET")
 
 	// Middleware pour l'authentification
 	authMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if _, err := r.Cookie("user_id"); err != nil {
+			if handlers.GetCurrentUser(r) == nil {
 				http.Redirect(w, r, "/login", http.StatusSeeOther)
 				return
 			}
@@ -122,6 +175,10 @@ func main() {
 	protected.HandleFunc("/profile", profileHandler.ShowProfile).Methods("GET")
 	protected.HandleFunc("/upload-avatar", profileHandler.UploadAvatar).Methods("POST")
 	protected.HandleFunc("/update-profile", profileHandler.UpdateProfile).Methods("POST")
+	protected.HandleFunc("/revoke-session/{id:[0-9]+}", profileHandler.RevokeSession).Methods("POST")
+	protected.HandleFunc("/revoke-all-sessions", profileHandler.RevokeAllSessions).Methods("POST")
+	protected.HandleFunc("/api-tokens", profileHandler.CreateAPIToken).Methods("POST")
+	protected.HandleFunc("/revoke-api-token/{id:[0-9]+}", profileHandler.RevokeAPIToken).Methods("POST")
 	protected.HandleFunc("/notifications", notificationHandler.ShowNotifications).Methods("GET")
 
 	// Configuration du serveur HTTP
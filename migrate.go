@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"forum/database"
+)
+
+const migrateUsage = `Usage: forum migrate <commande>
+
+Commandes:
+  up              applique les migrations en attente
+  rollback [n]    annule les n dernières migrations (1 par défaut)
+  status          affiche l'état des migrations`
+
+// runMigrateCommand exécute la sous-commande migrate et retourne le code de sortie
+func runMigrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, err := database.InitDB()
+	if err != nil {
+		log.Printf("Échec d'initialisation de la DB: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		if err := database.RunMigrations(db); err != nil {
+			log.Printf("Échec des migrations: %v", err)
+			return 1
+		}
+
+	case "rollback":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintf(os.Stderr, "Nombre de migrations invalide: %s\n", args[1])
+				return 2
+			}
+		}
+		if err := database.RollbackMigrations(db, steps); err != nil {
+			log.Printf("Échec de l'annulation: %v", err)
+			return 1
+		}
+
+	case "status":
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			log.Printf("Échec de la lecture des migrations: %v", err)
+			return 1
+		}
+		for _, state := range states {
+			status := "en attente"
+			if state.Applied {
+				status = "appliquée le " + state.AppliedAt.Format("02 Jan 2006 à 15:04")
+			}
+			fmt.Printf("%03d_%-30s %s\n", state.Version, state.Name, status)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 )
 
@@ -14,8 +15,21 @@ const (
 	ActivityDislike       ActivityType = "dislike"
 	ActivityUpdateProfile ActivityType = "update_profile"
 	ActivityDeletePost    ActivityType = "delete_post"
+	ActivityReportHandled ActivityType = "report_handled"
+	ActivityModeration    ActivityType = "moderation"
+	ActivityReply         ActivityType = "reply"
+	ActivityAnswer        ActivityType = "answer_accepted"
+
+	// ActivityReactionPrefix précède la clé des réactions autres que like et
+	// dislike : reaction_helpful, reaction_thanks...
+	ActivityReactionPrefix ActivityType = "reaction_"
 )
 
+// IsReaction indique une notification de réaction, vote compris
+func (t ActivityType) IsReaction() bool {
+	return t == ActivityLike || t == ActivityDislike || strings.HasPrefix(string(t), string(ActivityReactionPrefix))
+}
+
 type Activity struct {
 	ID          int64        `json:"id"`
 	UserID      int64        `json:"user_id"`
@@ -118,6 +132,49 @@ func (s *ActivityStore) GetNotificationsForUser(userID int64) ([]*Activity, erro
 	return activities, nil
 }
 
+// récupère au plus limit notifications d'un utilisateur antérieures à beforeID
+// (toutes si beforeID vaut 0), les plus récentes d'abord
+func (s *ActivityStore) GetNotificationsBefore(userID, beforeID int64, limit int) ([]*Activity, error) {
+	query := `
+		SELECT id, user_id, recipient_id, type, target_id, created_at, content, is_read
+		FROM activities
+		WHERE recipient_id = ? AND user_id != ? AND (? = 0 OR id < ?)
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := s.DB.Query(query, userID, userID, beforeID, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []*Activity{}
+	for rows.Next() {
+		var activity Activity
+		err := rows.Scan(
+			&activity.ID,
+			&activity.UserID,
+			&activity.RecipientID,
+			&activity.Type,
+			&activity.TargetID,
+			&activity.CreatedAt,
+			&activity.Content,
+			&activity.IsRead,
+		)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, &activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
 // récupère le nombre de notifications non lues
 func (s *ActivityStore) GetUnreadNotificationsCount(userID int64) (int, error) {
 	query := `
@@ -170,6 +227,12 @@ func (s *ActivityStore) MarkNotificationsAsRead(userID int64) error {
 	return err
 }
 
+// marque une notification comme lue ou non lue
+func (s *ActivityStore) SetRead(id int64, read bool) error {
+	_, err := s.DB.Exec(`UPDATE activities SET is_read = ? WHERE id = ?`, read, id)
+	return err
+}
+
 // supprime une activité/notification
 func (s *ActivityStore) Delete(id int64) error {
 	query := `
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// TokenScope limite les actions permises par un jeton d'API
+type TokenScope string
+
+const (
+	// TokenScopeRead n'autorise que les requêtes de lecture (GET, HEAD)
+	TokenScopeRead TokenScope = "read"
+	// TokenScopeWrite autorise toutes les requêtes de l'API
+	TokenScopeWrite TokenScope = "write"
+)
+
+// Préfixe des jetons d'API, qui les distingue des jetons de session
+const apiTokenPrefix = "sh_"
+
+// Intervalle minimal entre deux mises à jour de la date de dernière utilisation
+const apiTokenTouchInterval = time.Minute
+
+// ParseTokenScope convertit la portée choisie dans le formulaire
+func ParseTokenScope(value string) (TokenScope, bool) {
+	switch scope := TokenScope(value); scope {
+	case TokenScopeRead, TokenScopeWrite:
+		return scope, true
+	default:
+		return "", false
+	}
+}
+
+// Allows indique si la portée permet une requête de la méthode donnée
+func (s TokenScope) Allows(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return s == TokenScopeRead || s == TokenScopeWrite
+	default:
+		return s == TokenScopeWrite
+	}
+}
+
+// Label retourne le libellé affiché de la portée
+func (s TokenScope) Label() string {
+	if s == TokenScopeWrite {
+		return "Lecture et écriture"
+	}
+	return "Lecture seule"
+}
+
+// APIToken est un jeton d'API personnel, utilisé par les scripts et les bots
+// dans l'en-tête Authorization: Bearer
+type APIToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Scope      TokenScope `json:"scope"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"` // Zéro si le jeton n'a jamais servi
+}
+
+// APITokenStore gère les jetons d'API des utilisateurs
+type APITokenStore struct {
+	DB *sql.DB
+}
+
+// NewAPITokenStore crée une nouvelle instance de APITokenStore
+func NewAPITokenStore(db *sql.DB) *APITokenStore {
+	return &APITokenStore{DB: db}
+}
+
+const apiTokenColumns = `id, user_id, name, token_hash, scope, created_at, last_used_at`
+
+func scanAPIToken(scanner interface{ Scan(...interface{}) error }) (*APIToken, error) {
+	var token APIToken
+	var lastUsedAt sql.NullTime
+	err := scanner.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.Scope,
+		&token.CreatedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = lastUsedAt.Time
+	}
+	return &token, nil
+}
+
+// Create génère un jeton pour l'utilisateur et le retourne en clair : il ne
+// pourra plus être affiché ensuite, seule son empreinte est conservée.
+func (s *APITokenStore) Create(userID int64, name string, scope TokenScope) (*APIToken, string, error) {
+	secret, err := generateSessionToken()
+	if err != nil {
+		return nil, "", err
+	}
+	plain := apiTokenPrefix + secret
+
+	token := &APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashSessionToken(plain),
+		Scope:     scope,
+		CreatedAt: time.Now(),
+	}
+
+	err = s.DB.QueryRow(
+		`INSERT INTO api_tokens (user_id, name, token_hash, scope, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.Scope,
+		token.CreatedAt,
+	).Scan(&token.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, plain, nil
+}
+
+// GetByToken retrouve un jeton à partir de sa valeur en clair
+func (s *APITokenStore) GetByToken(plain string) (*APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+	return scanAPIToken(s.DB.QueryRow(query, hashSessionToken(plain)))
+}
+
+// GetByUserID récupère les jetons d'un utilisateur, les plus récents d'abord
+func (s *APITokenStore) GetByUserID(userID int64) ([]*APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Touch enregistre l'utilisation du jeton, au plus une fois par minute
+func (s *APITokenStore) Touch(token *APIToken) error {
+	now := time.Now()
+	if now.Sub(token.LastUsedAt) < apiTokenTouchInterval {
+		return nil
+	}
+	token.LastUsedAt = now
+	_, err := s.DB.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", now, token.ID)
+	return err
+}
+
+// DeleteByID révoque un jeton appartenant à un utilisateur
+func (s *APITokenStore) DeleteByID(id, userID int64) error {
+	res, err := s.DB.Exec("DELETE FROM api_tokens WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTokenScopeAllows(t *testing.T) {
+	tests := []struct {
+		scope  TokenScope
+		method string
+		want   bool
+	}{
+		{TokenScopeRead, http.MethodGet, true},
+		{TokenScopeRead, http.MethodHead, true},
+		{TokenScopeRead, http.MethodOptions, true},
+		{TokenScopeRead, http.MethodPost, false},
+		{TokenScopeRead, http.MethodPatch, false},
+		{TokenScopeRead, http.MethodPut, false},
+		{TokenScopeRead, http.MethodDelete, false},
+		{TokenScopeWrite, http.MethodGet, true},
+		{TokenScopeWrite, http.MethodPost, true},
+		{TokenScopeWrite, http.MethodPatch, true},
+		{TokenScopeWrite, http.MethodDelete, true},
+		{TokenScope(""), http.MethodGet, false},
+		{TokenScope("admin"), http.MethodPost, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.scope.Allows(tt.method); got != tt.want {
+			t.Errorf("TokenScope(%q).Allows(%s) = %v, want %v", tt.scope, tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestParseTokenScope(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   TokenScope
+		wantOK bool
+	}{
+		{"read", TokenScopeRead, true},
+		{"write", TokenScopeWrite, true},
+		{"", "", false},
+		{"Write", "", false},
+		{"admin", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseTokenScope(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseTokenScope(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
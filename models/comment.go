@@ -8,15 +8,52 @@ import (
 )
 
 type Comment struct {
-	ID           int64
-	PostID       int64
-	UserID       int64
-	Content      string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	LikeCount    int
-	DislikeCount int
-	Status       PostStatus
+	ID           int64      `json:"id"`
+	PostID       int64      `json:"post_id"`
+	ParentID     int64      `json:"parent_id,omitempty"`
+	UserID       int64      `json:"user_id"`
+	Content      string     `json:"content"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	LikeCount    int        `json:"like_count"`
+	DislikeCount int        `json:"dislike_count"`
+	Status       PostStatus `json:"status"`
+
+	// Compteurs par type de réaction, renseignés par ReactionStore si nécessaire
+	Reactions ReactionCounts `json:"reactions,omitempty"`
+
+	// Renseignés lors de l'assemblage du fil de discussion
+	Depth   int        `json:"-"`
+	Replies []*Comment `json:"-"`
+}
+
+// DefaultCommentMaxDepth est la profondeur d'imbrication par défaut des réponses
+const DefaultCommentMaxDepth = 4
+
+// CommentMaxDepth limite l'imbrication affichée : au-delà, les réponses
+// sont rattachées au dernier niveau autorisé
+var CommentMaxDepth = DefaultCommentMaxDepth
+
+const commentColumns = `id, post_id, COALESCE(parent_id, 0), user_id, content, created_at, updated_at, like_count, dislike_count, status`
+
+func scanComment(scanner interface{ Scan(...interface{}) error }) (*Comment, error) {
+	var c Comment
+	err := scanner.Scan(
+		&c.ID,
+		&c.PostID,
+		&c.ParentID,
+		&c.UserID,
+		&c.Content,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.LikeCount,
+		&c.DislikeCount,
+		&c.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
 }
 
 type CommentStore struct {
@@ -33,14 +70,20 @@ func (s *CommentStore) Create(comment *Comment) error {
 	comment.UpdatedAt = now // Assurez-vous que UpdatedAt est également défini
 
 	query := `
-        INSERT INTO comments (post_id, user_id, content, created_at, updated_at, status, like_count, dislike_count) 
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO comments (post_id, parent_id, user_id, content, created_at, updated_at, status, like_count, dislike_count) 
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
         RETURNING id
     `
 
+	var parentID interface{}
+	if comment.ParentID != 0 {
+		parentID = comment.ParentID
+	}
+
 	err := s.DB.QueryRow(
 		query,
 		comment.PostID,
+		parentID,
 		comment.UserID,
 		comment.Content,
 		comment.CreatedAt,
@@ -58,33 +101,14 @@ func (s *CommentStore) Create(comment *Comment) error {
 }
 
 func (s *CommentStore) GetByID(id int64) (*Comment, error) {
-	query := `SELECT id, post_id, user_id, content, created_at, updated_at, like_count, dislike_count, status FROM comments WHERE id = ?`
-
-	var comment Comment
-	err := s.DB.QueryRow(query, id).Scan(
-		&comment.ID,
-		&comment.PostID,
-		&comment.UserID,
-		&comment.Content,
-		&comment.CreatedAt,
-		&comment.UpdatedAt,
-		&comment.LikeCount,
-		&comment.DislikeCount,
-		&comment.Status,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &comment, nil
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE id = ?`
+	return scanComment(s.DB.QueryRow(query, id))
 }
 
 func (s *CommentStore) GetCommentsByPostID(postID int64) ([]*Comment, error) {
 	log.Printf("Tentative de récupération des commentaires pour le post ID: %d", postID)
 
-	query := `SELECT id, post_id, user_id, content, created_at, updated_at, like_count, dislike_count, status 
-              FROM comments WHERE post_id = ?`
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE post_id = ? ORDER BY created_at ASC, id ASC`
 
 	// Vérifiez d'abord si des commentaires existent
 	var count int
@@ -110,24 +134,12 @@ func (s *CommentStore) GetCommentsByPostID(postID int64) ([]*Comment, error) {
 
 	comments := make([]*Comment, 0, count)
 	for rows.Next() {
-		var c Comment
-		err := rows.Scan(
-			&c.ID,
-			&c.PostID,
-			&c.UserID,
-			&c.Content,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-			&c.LikeCount,
-			&c.DislikeCount,
-			&c.Status,
-		)
+		c, err := scanComment(rows)
 		if err != nil {
 			log.Printf("Erreur lors du scan d'un commentaire: %v", err)
 			return nil, fmt.Errorf("failed to scan comment: %w", err)
 		}
-		log.Printf("Commentaire récupéré: ID=%d, Content=%s", c.ID, c.Content)
-		comments = append(comments, &c)
+		comments = append(comments, c)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -138,6 +150,94 @@ func (s *CommentStore) GetCommentsByPostID(postID int64) ([]*Comment, error) {
 	log.Printf("Total de %d commentaires récupérés avec succès", len(comments))
 	return comments, nil
 }
+
+// ListByPostID retourne au plus limit commentaires d'un post dont l'ID suit
+// afterID, dans l'ordre de publication, limités à ceux que viewer peut voir
+// (voir Comment.VisibleTo ; nil pour un visiteur).
+func (s *CommentStore) ListByPostID(postID, afterID int64, limit int, viewer *User) ([]*Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE post_id = ? AND id > ?`
+	params := []interface{}{postID, afterID}
+	switch {
+	case viewer == nil:
+		query += ` AND status IN (` + placeholders(len(PublicPostStatuses)) + `)`
+		params = append(params, publicStatusParams()...)
+	case !viewer.IsModerator():
+		query += ` AND (status IN (` + placeholders(len(PublicPostStatuses)) + `)
+			OR (user_id = ? AND status IN (` + placeholders(len(AuthorVisibleStatuses)) + `)))`
+		params = append(params, publicStatusParams()...)
+		params = append(params, viewer.ID)
+		for _, status := range AuthorVisibleStatuses {
+			params = append(params, status)
+		}
+	}
+	query += ` ORDER BY id ASC LIMIT ?`
+	params = append(params, limit)
+
+	rows, err := s.DB.Query(query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return comments, nil
+}
+
+// BuildCommentTree assemble les commentaires d'un post en fil de discussion.
+// Les réponses dépassant maxDepth sont rattachées à leur ancêtre du dernier niveau
+// et celles dont le parent n'est pas visible remontent à la racine.
+func BuildCommentTree(comments []*Comment, maxDepth int) []*Comment {
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+
+	byID := make(map[int64]*Comment, len(comments))
+	for _, c := range comments {
+		c.Replies = nil
+		byID[c.ID] = c
+	}
+
+	var roots []*Comment
+	for _, c := range comments {
+		parent, exists := byID[c.ParentID]
+		if c.ParentID == 0 || !exists || parent == c {
+			c.Depth = 0
+			roots = append(roots, c)
+			continue
+		}
+
+		// Les commentaires sont triés par date : le parent a déjà sa profondeur
+		for parent.Depth >= maxDepth {
+			parent = byID[parent.ParentID]
+		}
+		c.Depth = parent.Depth + 1
+		parent.Replies = append(parent.Replies, c)
+	}
+
+	return roots
+}
+
+// ReplyCount compte toutes les réponses d'un commentaire, à tous les niveaux
+func (c *Comment) ReplyCount() int {
+	count := len(c.Replies)
+	for _, reply := range c.Replies {
+		count += reply.ReplyCount()
+	}
+	return count
+}
+
 func (s *CommentStore) Update(comment *Comment) error {
 	query := `
 		UPDATE comments 
@@ -156,6 +256,58 @@ func (s *CommentStore) Update(comment *Comment) error {
 	return err
 }
 
+// UpdateStatus modifie uniquement le statut d'un commentaire
+func (s *CommentStore) UpdateStatus(id int64, status PostStatus) error {
+	_, err := s.DB.Exec("UPDATE comments SET status = ?, updated_at = ? WHERE id = ?", status, time.Now(), id)
+	return err
+}
+
+// Hide masque un commentaire signalé, en conservant son statut pour Unhide
+func (s *CommentStore) Hide(id int64) error {
+	_, err := s.DB.Exec(`
+		UPDATE comments SET status_before_report = status, status = ?
+		WHERE id = ? AND status != ?
+	`, PostStatusHidden, id, PostStatusHidden)
+	return err
+}
+
+// Unhide rétablit le statut d'un commentaire masqué tel qu'avant le signalement
+func (s *CommentStore) Unhide(id int64) error {
+	_, err := s.DB.Exec(`
+		UPDATE comments
+		SET status = CASE WHEN status_before_report = '' THEN ? ELSE status_before_report END,
+			status_before_report = ''
+		WHERE id = ? AND status = ?
+	`, StatusApproved, id, PostStatusHidden)
+	return err
+}
+
+// GetByStatus récupère les commentaires ayant un statut donné, les plus anciens d'abord
+func (s *CommentStore) GetByStatus(status PostStatus) ([]*Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE status = ? ORDER BY created_at ASC`
+
+	rows, err := s.DB.Query(query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*Comment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return comments, nil
+}
+
 func (s *CommentStore) Delete(id int64) error {
 	query := `DELETE FROM comments WHERE id = ?`
 
@@ -182,16 +334,30 @@ func (c *Comment) CanEdit(userID int64, userRole UserRole) bool {
 	return userID == c.UserID || userRole >= RoleModerator
 }
 
-// UpdateLikeCount met à jour le nombre de likes d'un commentaire
-func (s *CommentStore) UpdateLikeCount(commentID int64, delta int) error {
-	query := `UPDATE comments SET like_count = like_count + ? WHERE id = ?`
-	_, err := s.DB.Exec(query, delta, commentID)
-	return err
-}
+// AuthorVisibleStatuses liste les statuts des commentaires non publics que
+// leur auteur voit encore : en attente de validation ou rejetés
+var AuthorVisibleStatuses = []PostStatus{StatusPending, StatusRejected}
 
-// UpdateDislikeCount met à jour le nombre de dislikes d'un commentaire
-func (s *CommentStore) UpdateDislikeCount(commentID int64, delta int) error {
-	query := `UPDATE comments SET dislike_count = dislike_count + ? WHERE id = ?`
-	_, err := s.DB.Exec(query, delta, commentID)
-	return err
+// VisibleTo indique si le commentaire est visible par l'utilisateur (nil pour
+// un visiteur) : public, en attente ou rejeté pour son auteur, et sans
+// restriction pour les modérateurs. ListByPostID applique la même règle en SQL.
+func (c *Comment) VisibleTo(user *User) bool {
+	if c.Status.IsPublic() {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	if user.IsModerator() {
+		return true
+	}
+	if user.ID != c.UserID {
+		return false
+	}
+	for _, status := range AuthorVisibleStatuses {
+		if c.Status == status {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// threadShape décrit un fil ligne à ligne, "id:profondeur" indenté sous son
+// parent, pour comparer les arbres
+func threadShape(comments []*Comment) []string {
+	var shape []string
+	var walk func(c *Comment, prefix string)
+	walk = func(c *Comment, prefix string) {
+		shape = append(shape, fmt.Sprintf("%s%d:%d", prefix, c.ID, c.Depth))
+		for _, reply := range c.Replies {
+			walk(reply, prefix+"  ")
+		}
+	}
+	for _, c := range comments {
+		walk(c, "")
+	}
+	return shape
+}
+
+func TestBuildCommentTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		parents  []int64 // parents[i] est le parent du commentaire i+1
+		maxDepth int
+		want     []string
+	}{
+		{
+			name:     "commentaires à plat",
+			parents:  []int64{0, 0, 0},
+			maxDepth: 4,
+			want:     []string{"1:0", "2:0", "3:0"},
+		},
+		{
+			name:     "réponses imbriquées",
+			parents:  []int64{0, 1, 2, 1},
+			maxDepth: 4,
+			want:     []string{"1:0", "  2:1", "    3:2", "  4:1"},
+		},
+		{
+			name:     "réponses au-delà de la profondeur maximale",
+			parents:  []int64{0, 1, 2, 3, 4},
+			maxDepth: 2,
+			want:     []string{"1:0", "  2:1", "    3:2", "    4:2", "    5:2"},
+		},
+		{
+			name:     "profondeur maximale invalide ramenée à 1",
+			parents:  []int64{0, 1, 2},
+			maxDepth: 0,
+			want:     []string{"1:0", "  2:1", "  3:1"},
+		},
+		{
+			name:     "parent absent ou commentaire son propre parent",
+			parents:  []int64{0, 9, 3},
+			maxDepth: 4,
+			want:     []string{"1:0", "2:0", "3:0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := make([]*Comment, len(tt.parents))
+			for i, parentID := range tt.parents {
+				comments[i] = &Comment{ID: int64(i + 1), ParentID: parentID}
+			}
+
+			got := threadShape(BuildCommentTree(comments, tt.maxDepth))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildCommentTree() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommentTreeIsRepeatable(t *testing.T) {
+	comments := []*Comment{{ID: 1}, {ID: 2, ParentID: 1}}
+
+	BuildCommentTree(comments, 4)
+	roots := BuildCommentTree(comments, 4)
+
+	if len(roots) != 1 || len(roots[0].Replies) != 1 {
+		t.Fatalf("second build gave %d roots and %d replies, want 1 and 1", len(roots), len(roots[0].Replies))
+	}
+	if count := roots[0].ReplyCount(); count != 1 {
+		t.Errorf("ReplyCount() = %d, want 1", count)
+	}
+}
+
+func TestListByPostIDMatchesVisibleTo(t *testing.T) {
+	db := newTestDB(t)
+	store := NewCommentStore(db)
+	author := newTestUser(t, db, "alice")
+	reader := newTestUser(t, db, "bob")
+	moderator := newTestUser(t, db, "carol")
+	moderator.Role = RoleModerator
+
+	post := &Post{UserID: author.ID, Title: "Canaux", Content: "Question", Status: StatusApproved}
+	if err := NewPostStore(db).Create(post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	var comments []*Comment
+	statuses := []PostStatus{StatusApproved, PostStatusActive, StatusPending, StatusRejected, PostStatusHidden, PostStatusDeleted}
+	for _, status := range statuses {
+		comment := &Comment{PostID: post.ID, UserID: author.ID, Content: string(status), Status: status}
+		if err := store.Create(comment); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	viewers := map[string]*User{"visiteur": nil, "auteur": author, "lecteur": reader, "modérateur": moderator}
+	for name, viewer := range viewers {
+		var want []int64
+		for _, comment := range comments {
+			if comment.VisibleTo(viewer) {
+				want = append(want, comment.ID)
+			}
+		}
+
+		listed, err := store.ListByPostID(post.ID, 0, len(comments), viewer)
+		if err != nil {
+			t.Fatalf("%s: ListByPostID() failed: %v", name, err)
+		}
+		var got []int64
+		for _, comment := range listed {
+			got = append(got, comment.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ListByPostID() = %v, VisibleTo() keeps %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// PremoderationMode détermine quels contenus doivent être validés avant publication
+type PremoderationMode string
+
+const (
+	PremoderationOff         PremoderationMode = "off"
+	PremoderationNewAccounts PremoderationMode = "new_accounts"
+	PremoderationAll         PremoderationMode = "all"
+)
+
+// DefaultNewAccountAge est l'âge en dessous duquel un compte est considéré comme nouveau
+const DefaultNewAccountAge = 7 * 24 * time.Hour
+
+// ModerationPolicy décrit la configuration de la pré-modération
+type ModerationPolicy struct {
+	Mode          PremoderationMode
+	NewAccountAge time.Duration
+}
+
+// LoadModerationPolicy lit la configuration depuis les variables d'environnement
+// PREMODERATION (off, new_accounts, all) et PREMODERATION_ACCOUNT_AGE (ex: 72h)
+func LoadModerationPolicy() *ModerationPolicy {
+	policy := &ModerationPolicy{
+		Mode:          PremoderationOff,
+		NewAccountAge: DefaultNewAccountAge,
+	}
+
+	switch mode := PremoderationMode(os.Getenv("PREMODERATION")); mode {
+	case "":
+	case PremoderationOff, PremoderationNewAccounts, PremoderationAll:
+		policy.Mode = mode
+	default:
+		log.Printf("Mode de pré-modération inconnu %q, pré-modération désactivée", mode)
+	}
+
+	if value := os.Getenv("PREMODERATION_ACCOUNT_AGE"); value != "" {
+		age, err := time.ParseDuration(value)
+		if err != nil || age < 0 {
+			log.Printf("PREMODERATION_ACCOUNT_AGE invalide %q, valeur par défaut utilisée", value)
+		} else {
+			policy.NewAccountAge = age
+		}
+	}
+
+	return policy
+}
+
+// RequiresReview indique si un contenu publié par l'utilisateur doit être validé
+func (p *ModerationPolicy) RequiresReview(user *User) bool {
+	if p == nil || user == nil || user.IsModerator() {
+		return false
+	}
+	switch p.Mode {
+	case PremoderationAll:
+		return true
+	case PremoderationNewAccounts:
+		return time.Since(user.CreatedAt) < p.NewAccountAge
+	default:
+		return false
+	}
+}
+
+// InitialStatus retourne le statut attribué à un nouveau post ou commentaire
+func (p *ModerationPolicy) InitialStatus(user *User) PostStatus {
+	if p.RequiresReview(user) {
+		return StatusPending
+	}
+	return StatusApproved
+}
+
+// EditStatus retourne le statut d'un contenu public après sa modification par
+// l'utilisateur : soumis à la pré-modération, il repasse en attente de
+// validation. Les contenus masqués, rejetés ou en attente gardent leur statut.
+func (p *ModerationPolicy) EditStatus(user *User, current PostStatus) PostStatus {
+	if current.IsPublic() && p.RequiresReview(user) {
+		return StatusPending
+	}
+	return current
+}
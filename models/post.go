@@ -2,9 +2,9 @@ package models
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
-	"net/http"
-	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,6 +20,42 @@ const (
 	StatusRejected     PostStatus = "rejected"
 )
 
+// PublicPostStatuses liste les statuts des posts et commentaires visibles par
+// tous : c'est la seule définition de la visibilité, utilisée par IsPublic et
+// par les requêtes SQL. Les contenus en attente de validation ou rejetés n'en
+// font pas partie.
+var PublicPostStatuses = []PostStatus{StatusApproved, PostStatusActive, PostStatusReported}
+
+// IsPublic indique si le statut rend le contenu visible par tous
+func (s PostStatus) IsPublic() bool {
+	for _, status := range PublicPostStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// PostType distingue les discussions des questions attendant une réponse
+type PostType string
+
+const (
+	PostTypeDiscussion PostType = "discussion"
+	PostTypeQuestion   PostType = "question"
+)
+
+// Valeurs du filtre résolu / non résolu
+const (
+	SolvedOnly   = "solved"
+	UnsolvedOnly = "unsolved"
+)
+
+// Combinaison des tags d'un filtre : tous requis ou au moins un
+const (
+	TagMatchAll = "all"
+	TagMatchAny = "any"
+)
+
 // Post représente un article du forum
 type Post struct {
 	ID           int64      `json:"id"`
@@ -34,19 +70,64 @@ type Post struct {
 	Tags         []*Tag     `json:"tags"` // Utilise Tag au lieu de Category
 	ImageURL     string     `json:"image_url"`
 	ImageType    string     `json:"image_type"`
+	Type         PostType   `json:"type"`
+	// Commentaire accepté comme réponse (questions uniquement), 0 si aucun
+	AcceptedCommentID int64 `json:"accepted_comment_id,omitempty"`
+	// Date de mise à la corbeille, zéro si le post n'est pas supprimé
+	DeletedAt time.Time `json:"deleted_at,omitempty"`
+	// Extrait du texte correspondant à la recherche, termes entourés de
+	// SnippetMatchStart et SnippetMatchEnd (résultats de recherche uniquement)
+	Snippet string `json:"snippet,omitempty"`
+	// Compteurs par type de réaction, renseignés par ReactionStore si nécessaire
+	Reactions ReactionCounts `json:"reactions,omitempty"`
+
+	// Valeur du tri, renseignée par FilterPosts en pagination par curseur
+	sortKey interface{}
 }
 
+// DefaultTrashRetention est la durée de conservation par défaut des posts supprimés
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashRetention est le délai pendant lequel un post supprimé peut être restauré
+// avant d'être définitivement effacé
+var TrashRetention = DefaultTrashRetention
+
 // PostFilter contient les critères de filtrage pour les posts
 type PostFilter struct {
-	Tag        int64            `json:"tag"`
-	Search     string           `json:"search"`
-	SortBy     string           `json:"sort_by"`
-	SortOrder  string           `json:"sort_order"`
-	Status     PostStatus       `json:"status"`
-	UserID     int64            `json:"user_id"`
-	DateFrom   time.Time        `json:"date_from"`
-	DateTo     time.Time        `json:"date_to"`
-	Pagination PaginationParams `json:"pagination"`
+	Search            string           `json:"search"`
+	SortBy            string           `json:"sort_by"`
+	SortOrder         string           `json:"sort_order"`
+	Status            PostStatus       `json:"status"`
+	Statuses          []PostStatus     `json:"statuses"`
+	Type              PostType         `json:"type"`
+	Solved            string           `json:"solved"` // SolvedOnly, UnsolvedOnly ou vide
+	UserID            int64            `json:"user_id"`
+	DateFrom          time.Time        `json:"date_from"`
+	DateTo            time.Time        `json:"date_to"`
+	Tags              []int64          `json:"tags"`
+	TagMatch          string           `json:"tag_match"`    // Combinaison de Tags : TagMatchAll (par défaut) ou TagMatchAny
+	ExcludeTags       []int64          `json:"exclude_tags"` // Aucun de ces tags
+	HasImage          bool             `json:"has_image"`
+	HasAcceptedAnswer bool             `json:"has_accepted_answer"` // Questions résolues uniquement
+	MinLikes          int              `json:"min_likes"`
+	MinComments       int              `json:"min_comments"` // Commentaires publics
+	Pagination        PaginationParams `json:"pagination"`
+	// Pagination par curseur : si renseigné, FilterPosts retourne les
+	// Pagination.PerPage posts suivant ce curseur (la première page pour un
+	// curseur vide) au lieu d'utiliser Pagination.Page
+	Cursor *PostCursor `json:"-"`
+}
+
+// PostCursor repère un post dans une liste triée : la valeur du tri pour ce
+// post, et son ID pour départager les égalités
+type PostCursor struct {
+	Value interface{} `json:"v"`
+	ID    int64       `json:"id"`
+}
+
+// IsZero indique que le curseur désigne le début de la liste
+func (c *PostCursor) IsZero() bool {
+	return c == nil || c.ID == 0
 }
 
 type PaginationParams struct {
@@ -67,11 +148,15 @@ func NewPostStore(db *sql.DB) *PostStore {
 // Create ajoute un nouveau post
 func (s *PostStore) Create(post *Post) error {
 	query := `
-		INSERT INTO posts (user_id, title, content, created_at, status, image_url, image_type) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO posts (user_id, title, content, created_at, status, image_url, image_type, post_type) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		RETURNING id
 	`
 
+	if post.Type == "" {
+		post.Type = PostTypeDiscussion
+	}
+
 	err := s.DB.QueryRow(
 		query,
 		post.UserID,
@@ -81,17 +166,20 @@ func (s *PostStore) Create(post *Post) error {
 		post.Status,
 		post.ImageURL,
 		post.ImageType,
+		post.Type,
 	).Scan(&post.ID)
 
 	return err
 }
 
-// GetByID récupère un post par son ID
-func (s *PostStore) GetByID(id int64) (*Post, error) {
-	query := `SELECT id, user_id, title, content, created_at, updated_at, like_count, dislike_count, status, image_url, image_type FROM posts WHERE id = ?`
+const postColumns = `p.id, p.user_id, p.title, p.content, p.created_at, p.updated_at, p.like_count, p.dislike_count,
+	p.status, p.image_url, p.image_type, p.post_type, COALESCE(p.accepted_comment_id, 0), p.deleted_at`
 
+// scanPost lit les colonnes postColumns, suivies des éventuelles colonnes extra
+func scanPost(scanner interface{ Scan(...interface{}) error }, extra ...interface{}) (*Post, error) {
 	var post Post
-	err := s.DB.QueryRow(query, id).Scan(
+	var deletedAt sql.NullTime
+	dest := []interface{}{
 		&post.ID,
 		&post.UserID,
 		&post.Title,
@@ -103,20 +191,22 @@ func (s *PostStore) GetByID(id int64) (*Post, error) {
 		&post.Status,
 		&post.ImageURL,
 		&post.ImageType,
-	)
-
+		&post.Type,
+		&post.AcceptedCommentID,
+		&deletedAt,
+	}
+	err := scanner.Scan(append(dest, extra...)...)
 	if err != nil {
 		return nil, err
 	}
-
+	if deletedAt.Valid {
+		post.DeletedAt = deletedAt.Time
+	}
 	return &post, nil
 }
 
-// GetAllPosts récupère tous les posts avec pagination
-func (s *PostStore) GetAllPosts(page, perPage int) ([]*Post, error) {
-	query := `SELECT id, user_id, title, content, created_at, updated_at, like_count, dislike_count, status, image_url, image_type FROM posts LIMIT ? OFFSET ?`
-
-	rows, err := s.DB.Query(query, perPage, (page-1)*perPage)
+func (s *PostStore) queryPosts(query string, args ...interface{}) ([]*Post, error) {
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -124,24 +214,11 @@ func (s *PostStore) GetAllPosts(page, perPage int) ([]*Post, error) {
 
 	var posts []*Post
 	for rows.Next() {
-		var post Post
-		err := rows.Scan(
-			&post.ID,
-			&post.UserID,
-			&post.Title,
-			&post.Content,
-			&post.CreatedAt,
-			&post.UpdatedAt,
-			&post.LikeCount,
-			&post.DislikeCount,
-			&post.Status,
-			&post.ImageURL,
-			&post.ImageType,
-		)
+		post, err := scanPost(rows)
 		if err != nil {
 			return nil, err
 		}
-		posts = append(posts, &post)
+		posts = append(posts, post)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -151,87 +228,33 @@ func (s *PostStore) GetAllPosts(page, perPage int) ([]*Post, error) {
 	return posts, nil
 }
 
-// GetPostsByUserID récupère tous les posts d'un utilisateur
-func (s *PostStore) GetPostsByUserID(userID int64) ([]*Post, error) {
-	query := `SELECT id, user_id, title, content, created_at, updated_at, like_count, dislike_count, status, image_url, image_type FROM posts WHERE user_id = ?`
-
-	rows, err := s.DB.Query(query, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var posts []*Post
-	for rows.Next() {
-		var post Post
-		err := rows.Scan(
-			&post.ID,
-			&post.UserID,
-			&post.Title,
-			&post.Content,
-			&post.CreatedAt,
-			&post.UpdatedAt,
-			&post.LikeCount,
-			&post.DislikeCount,
-			&post.Status,
-			&post.ImageURL,
-			&post.ImageType,
-		)
-		if err != nil {
-			return nil, err
-		}
-		posts = append(posts, &post)
-	}
+// GetByID récupère un post par son ID
+func (s *PostStore) GetByID(id int64) (*Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts p WHERE p.id = ?`
+	return scanPost(s.DB.QueryRow(query, id))
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
+// GetAllPosts récupère tous les posts avec pagination
+func (s *PostStore) GetAllPosts(page, perPage int) ([]*Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts p WHERE p.status != 'deleted' LIMIT ? OFFSET ?`
+	return s.queryPosts(query, perPage, (page-1)*perPage)
+}
 
-	return posts, nil
+// GetPostsByUserID récupère tous les posts d'un utilisateur
+func (s *PostStore) GetPostsByUserID(userID int64) ([]*Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts p WHERE p.user_id = ? AND p.status != 'deleted'`
+	return s.queryPosts(query, userID)
 }
 
 // GetPostsByTag récupère tous les posts associés à un tag spécifique
 func (s *PostStore) GetPostsByTag(tagID int64) ([]*Post, error) {
 	query := `
-		SELECT p.id, p.user_id, p.title, p.content, p.created_at, p.updated_at, p.like_count, p.dislike_count, p.status, p.image_url, p.image_type 
+		SELECT ` + postColumns + `
 		FROM posts p
 		JOIN post_tags pt ON p.id = pt.post_id
-		WHERE pt.tag_id = ?
+		WHERE pt.tag_id = ? AND p.status != 'deleted'
 	`
-
-	rows, err := s.DB.Query(query, tagID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var posts []*Post
-	for rows.Next() {
-		var post Post
-		err := rows.Scan(
-			&post.ID,
-			&post.UserID,
-			&post.Title,
-			&post.Content,
-			&post.CreatedAt,
-			&post.UpdatedAt,
-			&post.LikeCount,
-			&post.DislikeCount,
-			&post.Status,
-			&post.ImageURL,
-			&post.ImageType,
-		)
-		if err != nil {
-			return nil, err
-		}
-		posts = append(posts, &post)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return posts, nil
+	return s.queryPosts(query, tagID)
 }
 
 // FilterByTag est un alias de GetPostsByTag pour maintenir la compatibilité
@@ -241,13 +264,44 @@ func (s *PostStore) FilterByTag(tagID int64) ([]*Post, error) {
 
 // Update met à jour un post existant
 func (s *PostStore) Update(post *Post) error {
+	return updatePost(s.DB, post)
+}
+
+// UpdateWithRevision met à jour un post modifié par editorID et, si son titre
+// ou son contenu change, enregistre sa nouvelle version dans la même transaction
+func (s *PostStore) UpdateWithRevision(before, after *Post, editorID int64) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updatePost(tx, after); err != nil {
+		return fmt.Errorf("failed to update post: %w", err)
+	}
+	if before.Title != after.Title || before.Content != after.Content {
+		if err := recordRevision(tx, before, after, editorID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func updatePost(db interface {
+	Exec(string, ...interface{}) (sql.Result, error)
+}, post *Post) error {
 	query := `
 		UPDATE posts 
-		SET title = ?, content = ?, updated_at = ?, status = ?, image_url = ?, image_type = ?
+		SET title = ?, content = ?, updated_at = ?, status = ?, image_url = ?, image_type = ?, post_type = ?
 		WHERE id = ?
 	`
 
-	_, err := s.DB.Exec(
+	if post.Type == "" {
+		post.Type = PostTypeDiscussion
+	}
+
+	_, err := db.Exec(
 		query,
 		post.Title,
 		post.Content,
@@ -255,70 +309,352 @@ func (s *PostStore) Update(post *Post) error {
 		post.Status,
 		post.ImageURL,
 		post.ImageType,
+		post.Type,
 		post.ID,
 	)
 
 	return err
 }
 
+// UpdateStatus modifie uniquement le statut d'un post
+func (s *PostStore) UpdateStatus(id int64, status PostStatus) error {
+	_, err := s.DB.Exec("UPDATE posts SET status = ?, updated_at = ? WHERE id = ?", status, time.Now(), id)
+	return err
+}
+
+// MarkReported passe un post au statut signalé. Le statut précédent est
+// conservé pour être rétabli si le signalement est classé sans suite.
+func (s *PostStore) MarkReported(id int64) error {
+	_, err := s.DB.Exec(`
+		UPDATE posts SET status_before_report = status, status = ?
+		WHERE id = ? AND status NOT IN (?, ?)
+	`, PostStatusReported, id, PostStatusReported, PostStatusHidden)
+	return err
+}
+
+// Hide masque un post signalé, en conservant le statut qu'il avait avant le signalement
+func (s *PostStore) Hide(id int64) error {
+	_, err := s.DB.Exec(`
+		UPDATE posts
+		SET status_before_report = CASE WHEN status = ? THEN status_before_report ELSE status END,
+			status = ?
+		WHERE id = ? AND status != ?
+	`, PostStatusReported, PostStatusHidden, id, PostStatusHidden)
+	return err
+}
+
+// Unhide rétablit le statut d'un post signalé ou masqué tel qu'avant le signalement
+func (s *PostStore) Unhide(id int64) error {
+	_, err := s.DB.Exec(`
+		UPDATE posts
+		SET status = CASE WHEN status_before_report = '' THEN ? ELSE status_before_report END,
+			status_before_report = ''
+		WHERE id = ? AND status IN (?, ?)
+	`, StatusApproved, id, PostStatusReported, PostStatusHidden)
+	return err
+}
+
+// SetAcceptedAnswer marque un commentaire comme réponse acceptée (0 pour retirer l'acceptation)
+func (s *PostStore) SetAcceptedAnswer(postID, commentID int64) error {
+	var accepted interface{}
+	if commentID != 0 {
+		accepted = commentID
+	}
+	_, err := s.DB.Exec("UPDATE posts SET accepted_comment_id = ? WHERE id = ?", accepted, postID)
+	return err
+}
+
+// Delete met un post à la corbeille : il reste restaurable jusqu'à sa purge
 func (s *PostStore) Delete(id int64) error {
+	_, err := s.DB.Exec(`
+		UPDATE posts
+		SET status_before_delete = status, status = ?, deleted_at = ?
+		WHERE id = ? AND status != ?
+	`, PostStatusDeleted, time.Now(), id, PostStatusDeleted)
+	return err
+}
+
+// Restore sort un post de la corbeille avec le statut qu'il avait avant sa suppression
+func (s *PostStore) Restore(id int64) error {
+	_, err := s.DB.Exec(`
+		UPDATE posts
+		SET status = CASE WHEN status_before_delete = '' THEN ? ELSE status_before_delete END,
+			status_before_delete = '', deleted_at = NULL
+		WHERE id = ? AND status = ?
+	`, StatusApproved, id, PostStatusDeleted)
+	return err
+}
+
+// GetDeletedByUserID retourne la corbeille d'un utilisateur, les suppressions récentes d'abord
+func (s *PostStore) GetDeletedByUserID(userID int64) ([]*Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts p WHERE p.user_id = ? AND p.status = ? ORDER BY p.deleted_at DESC`
+	return s.queryPosts(query, userID, PostStatusDeleted)
+}
+
+// GetExpiredDeleted retourne les posts supprimés avant la date donnée
+func (s *PostStore) GetExpiredDeleted(before time.Time) ([]*Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts p WHERE p.status = ? AND p.deleted_at < ?`
+	return s.queryPosts(query, PostStatusDeleted, before)
+}
+
+// Purge efface définitivement un post, avec ses commentaires, réactions et tags
+func (s *PostStore) Purge(id int64) error {
 	_, err := s.DB.Exec("DELETE FROM posts WHERE id = ?", id)
 	return err
 }
 
-// FilterPosts applique des filtres dynamiques aux posts
-func (s *PostStore) FilterPosts(filter PostFilter) ([]*Post, error) {
-	log.Println("Filtrage des posts avec les critères fournis")
+// RestoreDeadline retourne la date limite de restauration d'un post supprimé
+func (p *Post) RestoreDeadline() time.Time {
+	return p.DeletedAt.Add(TrashRetention)
+}
 
-	query := `
-        SELECT p.id, p.user_id, p.title, p.content, p.created_at, p.updated_at, p.like_count, p.dislike_count, p.status, p.image_url, p.image_type 
-        FROM posts p
-        WHERE 1=1
-    `
+// searchColumns ajoute l'extrait et le score des résultats d'une recherche.
+// L'extrait vient du post, ou à défaut du premier commentaire correspondant.
+// Le titre pèse dix fois plus que le contenu ; les posts trouvés uniquement
+// par leurs commentaires sont classés après les autres (score 0).
+// Paramètres : l'expression MATCH, l'expression MATCH puis les statuts publics,
+// et à nouveau l'expression MATCH.
+var searchColumns = `COALESCE(
+		(SELECT snippet(posts_fts, -1, '` + SnippetMatchStart + `', '` + SnippetMatchEnd + `', '…', 24)
+			FROM posts_fts WHERE posts_fts MATCH ? AND rowid = p.id),
+		(SELECT snippet(comments_fts, 0, '` + SnippetMatchStart + `', '` + SnippetMatchEnd + `', '…', 24)
+			FROM comments_fts JOIN comments c ON c.id = comments_fts.rowid
+			WHERE comments_fts MATCH ? AND c.post_id = p.id AND c.status IN (` + placeholders(len(PublicPostStatuses)) + `)
+			LIMIT 1)
+	) AS snippet,
+	COALESCE((SELECT bm25(posts_fts, 10.0, 1.0) FROM posts_fts WHERE posts_fts MATCH ? AND rowid = p.id), 0) AS relevance`
+
+// publicCommentCount compte les commentaires publics d'un post.
+// Paramètres : les statuts publics (publicStatusParams).
+var publicCommentCount = `(SELECT COUNT(*) FROM comments c
+	WHERE c.post_id = p.id AND c.status IN (` + placeholders(len(PublicPostStatuses)) + `))`
+
+// lastActivity est la date, en jour julien, du dernier commentaire public d'un
+// post, ou à défaut de sa publication. Les dates stockées n'ont pas toutes le
+// même format : elles sont converties avant d'être comparées.
+// Paramètres : les statuts publics (publicStatusParams).
+var lastActivity = `MAX(julianday(p.created_at), COALESCE((SELECT MAX(julianday(c.created_at)) FROM comments c
+	WHERE c.post_id = p.id AND c.status IN (` + placeholders(len(PublicPostStatuses)) + `)), julianday(p.created_at)))`
+
+// hotRanking classe les posts par score décroissant avec l'âge :
+// (likes - dislikes + 1) / (âge en heures + 2)². Le carré remplace une puissance
+// réelle, SQLite n'ayant pas de fonctions mathématiques par défaut.
+const hotRanking = `((p.like_count - p.dislike_count + 1)
+	/ ((julianday('now') - julianday(p.created_at)) * 24 + 2)
+	/ ((julianday('now') - julianday(p.created_at)) * 24 + 2))`
+
+// cursorSortKey retourne l'expression SQL de la valeur d'un tri paginé par
+// curseur, et ses paramètres. Les dates sont comparées en jours juliens : les
+// dates stockées n'ont pas toutes le même format. Les tris par pertinence et
+// par popularité récente dépendent de la requête ou de l'heure et ne peuvent
+// pas être paginés par curseur.
+func cursorSortKey(sortBy string) (string, []interface{}, error) {
+	switch sortBy {
+	case "", "date":
+		return "julianday(p.created_at)", nil, nil
+	case "likes":
+		return "p.like_count", nil, nil
+	case "dislikes":
+		return "p.dislike_count", nil, nil
+	case "score":
+		return "(p.like_count - p.dislike_count)", nil, nil
+	case "comments":
+		return publicCommentCount, publicStatusParams(), nil
+	case "activity":
+		return lastActivity, publicStatusParams(), nil
+	case "title":
+		return "p.title", nil, nil
+	default:
+		return "", nil, fmt.Errorf("sort %q does not support cursor pagination", sortBy)
+	}
+}
+
+// Cursor retourne le curseur désignant ce post dans la liste dont il est issu.
+// Il n'est renseigné que pour les posts obtenus en pagination par curseur.
+func (p *Post) Cursor() *PostCursor {
+	return &PostCursor{Value: p.sortKey, ID: p.ID}
+}
+
+// publicStatusParams retourne les statuts publics, en paramètres de requête
+func publicStatusParams() []interface{} {
+	params := make([]interface{}, 0, len(PublicPostStatuses))
+	for _, status := range PublicPostStatuses {
+		params = append(params, status)
+	}
+	return params
+}
+
+// filterConditions construit la clause WHERE commune à FilterPosts et CountPosts
+func filterConditions(filter PostFilter) (string, []interface{}) {
+	where := " WHERE 1=1"
 	var params []interface{}
 
-	// Appliquer les filtres
 	if filter.UserID != 0 {
-		query += " AND p.user_id = ?"
+		where += " AND p.user_id = ?"
 		params = append(params, filter.UserID)
 	}
 
-	if filter.Tag != 0 {
-		query += " AND p.id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)"
-		params = append(params, filter.Tag)
+	if len(filter.Tags) > 0 {
+		if filter.TagMatch == TagMatchAny {
+			where += " AND p.id IN (SELECT post_id FROM post_tags WHERE tag_id IN (" + placeholders(len(filter.Tags)) + "))"
+		} else {
+			where += " AND p.id IN (SELECT post_id FROM post_tags WHERE tag_id IN (" + placeholders(len(filter.Tags)) + ")" +
+				" GROUP BY post_id HAVING COUNT(DISTINCT tag_id) = ?)"
+		}
+		for _, tagID := range filter.Tags {
+			params = append(params, tagID)
+		}
+		if filter.TagMatch != TagMatchAny {
+			params = append(params, len(uniqueIDs(filter.Tags)))
+		}
+	}
+
+	if len(filter.ExcludeTags) > 0 {
+		where += " AND p.id NOT IN (SELECT post_id FROM post_tags WHERE tag_id IN (" + placeholders(len(filter.ExcludeTags)) + "))"
+		for _, tagID := range filter.ExcludeTags {
+			params = append(params, tagID)
+		}
 	}
 
-	if filter.Search != "" {
-		query += " AND (p.title LIKE ? OR p.content LIKE ?)"
+	if filter.Search != "" && !FullTextSearch {
+		where += " AND (p.title LIKE ? OR p.content LIKE ?)"
 		searchTerm := "%" + filter.Search + "%"
 		params = append(params, searchTerm, searchTerm)
+	} else if filter.Search != "" {
+		match := ParseSearchQuery(filter.Search)
+		if match == "" {
+			// Rien à chercher (ponctuation seule, exclusions seules) : aucun résultat
+			where += " AND 0"
+		} else {
+			// Le post correspond par son titre, son contenu ou l'un de ses commentaires publics
+			where += ` AND (p.id IN (SELECT rowid FROM posts_fts WHERE posts_fts MATCH ?)
+				OR p.id IN (SELECT c.post_id FROM comments_fts JOIN comments c ON c.id = comments_fts.rowid
+					WHERE comments_fts MATCH ? AND c.status IN (` + placeholders(len(PublicPostStatuses)) + `)))`
+			params = append(params, match, match)
+			params = append(params, publicStatusParams()...)
+		}
 	}
 
 	if filter.Status != "" {
-		query += " AND p.status = ?"
+		where += " AND p.status = ?"
 		params = append(params, filter.Status)
+	} else {
+		// Les posts à la corbeille n'apparaissent que si on les demande explicitement
+		where += " AND p.status != ?"
+		params = append(params, PostStatusDeleted)
+	}
+
+	if len(filter.Statuses) > 0 {
+		where += " AND p.status IN (" + placeholders(len(filter.Statuses)) + ")"
+		for _, status := range filter.Statuses {
+			params = append(params, status)
+		}
+	}
+
+	if filter.Type != "" {
+		where += " AND p.post_type = ?"
+		params = append(params, filter.Type)
+	}
+
+	// Le filtre résolu / non résolu ne concerne que les questions
+	switch filter.Solved {
+	case SolvedOnly:
+		where += " AND p.post_type = ? AND p.accepted_comment_id IS NOT NULL"
+		params = append(params, PostTypeQuestion)
+	case UnsolvedOnly:
+		where += " AND p.post_type = ? AND p.accepted_comment_id IS NULL"
+		params = append(params, PostTypeQuestion)
 	}
 
 	if !filter.DateFrom.IsZero() {
-		query += " AND p.created_at >= ?"
+		where += " AND julianday(p.created_at) >= julianday(?)"
 		params = append(params, filter.DateFrom)
 	}
 
 	if !filter.DateTo.IsZero() {
-		query += " AND p.created_at <= ?"
+		where += " AND julianday(p.created_at) <= julianday(?)"
 		params = append(params, filter.DateTo)
 	}
 
-	// Tri et pagination
+	if filter.HasImage {
+		where += " AND COALESCE(p.image_url, '') != ''"
+	}
+
+	if filter.HasAcceptedAnswer {
+		where += " AND p.accepted_comment_id IS NOT NULL"
+	}
+
+	if filter.MinLikes > 0 {
+		where += " AND p.like_count >= ?"
+		params = append(params, filter.MinLikes)
+	}
+
+	if filter.MinComments > 0 {
+		where += " AND " + publicCommentCount + " >= ?"
+		params = append(params, publicStatusParams()...)
+		params = append(params, filter.MinComments)
+	}
+
+	return where, params
+}
+
+// FilterPosts applique des filtres dynamiques aux posts
+func (s *PostStore) FilterPosts(filter PostFilter) ([]*Post, error) {
+	log.Println("Filtrage des posts avec les critères fournis")
+
+	where, params := filterConditions(filter)
+
+	// Recherche plein texte : extrait et score BM25 de chaque résultat
+	match := fullTextMatch(filter.Search)
+	columns := postColumns
+	var selectParams []interface{}
+	if match != "" {
+		columns += `, ` + searchColumns
+		selectParams = append(selectParams, match, match)
+		selectParams = append(selectParams, publicStatusParams()...)
+		selectParams = append(selectParams, match)
+	}
+	if filter.Cursor != nil {
+		return s.filterPostsAfter(filter, columns, selectParams, where, params, match != "")
+	}
+	params = append(selectParams, params...)
+
+	query := `SELECT ` + columns + ` FROM posts p` + where
+
+	// Tri et pagination, les dates comparées en jours juliens comme pour le curseur
 	switch filter.SortBy {
+	case "relevance":
+		if match != "" {
+			// BM25 est négatif, d'autant plus que le résultat est pertinent :
+			// l'ordre est inversé par rapport aux autres tris
+			if filter.SortOrder == "asc" {
+				query += " ORDER BY relevance DESC, julianday(p.created_at)"
+			} else {
+				query += " ORDER BY relevance ASC, julianday(p.created_at)"
+			}
+		} else {
+			query += " ORDER BY julianday(p.created_at)"
+		}
 	case "date":
-		query += " ORDER BY p.created_at"
+		query += " ORDER BY julianday(p.created_at)"
 	case "likes":
 		query += " ORDER BY p.like_count"
+	case "dislikes":
+		query += " ORDER BY p.dislike_count"
+	case "score":
+		query += " ORDER BY (p.like_count - p.dislike_count)"
+	case "comments":
+		query += " ORDER BY " + publicCommentCount
+		params = append(params, publicStatusParams()...)
+	case "activity":
+		query += " ORDER BY " + lastActivity
+		params = append(params, publicStatusParams()...)
+	case "hot":
+		query += " ORDER BY " + hotRanking
 	case "title":
 		query += " ORDER BY p.title"
 	default:
-		query += " ORDER BY p.created_at"
+		query += " ORDER BY julianday(p.created_at)"
 	}
 
 	if filter.SortOrder == "asc" {
@@ -327,16 +663,18 @@ func (s *PostStore) FilterPosts(filter PostFilter) ([]*Post, error) {
 		query += " DESC"
 	}
 
+	// À égalité, les posts les plus récents d'abord : la pagination reste stable
+	switch filter.SortBy {
+	case "dislikes", "likes", "score", "comments", "activity", "hot", "title":
+		query += ", julianday(p.created_at) DESC"
+	}
+
 	if filter.Pagination.PerPage > 0 {
 		query += " LIMIT ? OFFSET ?"
 		offset := (filter.Pagination.Page - 1) * filter.Pagination.PerPage
 		params = append(params, filter.Pagination.PerPage, offset)
 	}
 
-	// Journalisation pour le débogage
-	log.Printf("Requête SQL: %s", query)
-	log.Printf("Paramètres: %+v", params)
-
 	rows, err := s.DB.Query(query, params...)
 	if err != nil {
 		log.Printf("Erreur SQL: %v", err)
@@ -346,25 +684,22 @@ func (s *PostStore) FilterPosts(filter PostFilter) ([]*Post, error) {
 
 	var posts []*Post
 	for rows.Next() {
-		var post Post
-		err := rows.Scan(
-			&post.ID,
-			&post.UserID,
-			&post.Title,
-			&post.Content,
-			&post.CreatedAt,
-			&post.UpdatedAt,
-			&post.LikeCount,
-			&post.DislikeCount,
-			&post.Status,
-			&post.ImageURL,
-			&post.ImageType,
-		)
+		var post *Post
+		if match != "" {
+			var snippet sql.NullString
+			var relevance float64
+			post, err = scanPost(rows, &snippet, &relevance)
+			if post != nil {
+				post.Snippet = snippet.String
+			}
+		} else {
+			post, err = scanPost(rows)
+		}
 		if err != nil {
 			log.Printf("Erreur de scan: %v", err)
 			return nil, err
 		}
-		posts = append(posts, &post)
+		posts = append(posts, post)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -372,46 +707,86 @@ func (s *PostStore) FilterPosts(filter PostFilter) ([]*Post, error) {
 		return nil, err
 	}
 
-	log.Printf("Nombre de posts trouvés: %d", len(posts))
 	return posts, nil
 }
 
-// CountPosts compte le nombre total de posts selon les filtres
-func (s *PostStore) CountPosts(filter PostFilter) (int, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM posts p
-		WHERE 1=1
-	`
-	var params []interface{}
+// filterPostsAfter termine FilterPosts en pagination par curseur : les posts
+// sont triés par la valeur du tri puis par ID, et la page commence après le
+// curseur. Contrairement à un décalage, la page suivante reste exacte si des
+// posts sont publiés entre deux requêtes.
+func (s *PostStore) filterPostsAfter(filter PostFilter, columns string, selectParams []interface{}, where string, whereParams []interface{}, search bool) ([]*Post, error) {
+	key, keyParams, err := cursorSortKey(filter.SortBy)
+	if err != nil {
+		return nil, err
+	}
 
-	// Appliquer les filtres
-	if filter.UserID != 0 {
-		query += " AND p.user_id = ?"
-		params = append(params, filter.UserID)
+	// La valeur du tri est sélectionnée après les éventuelles colonnes de recherche
+	query := `SELECT ` + columns + `, ` + key + ` AS sort_key FROM posts p` + where
+	params := append(append(selectParams, keyParams...), whereParams...)
+
+	direction, comparison := "DESC", "<"
+	if filter.SortOrder == "asc" {
+		direction, comparison = "ASC", ">"
 	}
 
-	if filter.Tag != 0 {
-		query += " AND p.id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)"
-		params = append(params, filter.Tag)
+	if !filter.Cursor.IsZero() {
+		query += " AND (" + key + " " + comparison + " ? OR (" + key + " = ? AND p.id " + comparison + " ?))"
+		params = append(params, keyParams...)
+		params = append(params, filter.Cursor.Value)
+		params = append(params, keyParams...)
+		params = append(params, filter.Cursor.Value, filter.Cursor.ID)
 	}
 
-	if filter.Search != "" {
-		query += " AND (p.title LIKE ? OR p.content LIKE ?)"
-		searchTerm := "%" + filter.Search + "%"
-		params = append(params, searchTerm, searchTerm)
+	query += " ORDER BY sort_key " + direction + ", p.id " + direction
+
+	if filter.Pagination.PerPage > 0 {
+		query += " LIMIT ?"
+		params = append(params, filter.Pagination.PerPage)
 	}
 
-	if !filter.DateFrom.IsZero() {
-		query += " AND p.created_at >= ?"
-		params = append(params, filter.DateFrom)
+	rows, err := s.DB.Query(query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts: %w", err)
 	}
+	defer rows.Close()
 
-	if !filter.DateTo.IsZero() {
-		query += " AND p.created_at <= ?"
-		params = append(params, filter.DateTo)
+	var posts []*Post
+	for rows.Next() {
+		var post *Post
+		var sortKey interface{}
+		if search {
+			var snippet sql.NullString
+			var relevance float64
+			post, err = scanPost(rows, &snippet, &relevance, &sortKey)
+			if post != nil {
+				post.Snippet = snippet.String
+			}
+		} else {
+			post, err = scanPost(rows, &sortKey)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		// Le pilote peut retourner le texte en octets : le curseur est encodé en JSON
+		if b, ok := sortKey.([]byte); ok {
+			sortKey = string(b)
+		}
+		post.sortKey = sortKey
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
+	return posts, nil
+}
+
+// CountPosts compte le nombre total de posts selon les filtres
+func (s *PostStore) CountPosts(filter PostFilter) (int, error) {
+	where, params := filterConditions(filter)
+	query := `SELECT COUNT(*) FROM posts p` + where
+
 	var count int
 	err := s.DB.QueryRow(query, params...).Scan(&count)
 	if err != nil {
@@ -450,54 +825,6 @@ func (s *PostStore) RemoveAllTags(postID int64) error {
 	return err
 }
 
-// Like ajoute un like à un post
-func (s *PostStore) Like(postID, userID int64) error {
-	query := `
-		UPDATE posts 
-		SET like_count = like_count + 1 
-		WHERE id = ?
-	`
-
-	_, err := s.DB.Exec(query, postID)
-	return err
-}
-
-// Unlike supprime un like d'un post
-func (s *PostStore) Unlike(postID, userID int64) error {
-	query := `
-		UPDATE posts 
-		SET like_count = like_count - 1 
-		WHERE id = ?
-	`
-
-	_, err := s.DB.Exec(query, postID)
-	return err
-}
-
-// Dislike ajoute un dislike à un post
-func (s *PostStore) Dislike(postID, userID int64) error {
-	query := `
-		UPDATE posts 
-		SET dislike_count = dislike_count + 1 
-		WHERE id = ?
-	`
-
-	_, err := s.DB.Exec(query, postID)
-	return err
-}
-
-// Undislike supprime un dislike d'un post
-func (s *PostStore) Undislike(postID, userID int64) error {
-	query := `
-		UPDATE posts 
-		SET dislike_count = dislike_count - 1 
-		WHERE id = ?
-	`
-
-	_, err := s.DB.Exec(query, postID)
-	return err
-}
-
 // GetTagNames récupère les noms des tags d'un post
 func (p *Post) GetTagNames(tagStore *TagStore) []string {
 	tags, err := tagStore.GetTagsByPostID(p.ID)
@@ -512,6 +839,29 @@ func (p *Post) GetTagNames(tagStore *TagStore) []string {
 	return names
 }
 
+// CanEdit détermine si l'utilisateur peut modifier ou supprimer ce post
+func (p *Post) CanEdit(userID int64, userRole UserRole) bool {
+	return userID == p.UserID || userRole >= RoleModerator
+}
+
+// IsQuestion indique si le post attend une réponse
+func (p *Post) IsQuestion() bool {
+	return p.Type == PostTypeQuestion
+}
+
+// IsSolved indique si une réponse a été acceptée pour cette question
+func (p *Post) IsSolved() bool {
+	return p.IsQuestion() && p.AcceptedCommentID != 0
+}
+
+// ParsePostType convertit la valeur d'un formulaire en type de post
+func ParsePostType(value string) PostType {
+	if PostType(value) == PostTypeQuestion {
+		return PostTypeQuestion
+	}
+	return PostTypeDiscussion
+}
+
 // GetFormattedDate retourne la date formatée pour l'affichage
 func (p *Post) GetFormattedDate() string {
 	return p.CreatedAt.Format("Jan 02, 2006")
@@ -527,66 +877,49 @@ func (p *Post) LoadTags(tagStore *TagStore) error {
 	return nil
 }
 
-// Fonction utilitaire pour extraire l'ID utilisateur d'un cookie
-func getUserIDFromCookie(r *http.Request) int64 {
-	cookie, err := r.Cookie("user_id")
-	if err != nil {
-		return 0
-	}
-
-	userID, err := strconv.ParseInt(cookie.Value, 10, 64)
-	if err != nil {
-		return 0
-	}
-
-	return userID
-}
+// GetCommentCount compte les commentaires publics d'un post, comme FilterPosts
 func (s *PostStore) GetCommentCount(postID int64) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM comments WHERE post_id = ?`
-	err := s.DB.QueryRow(query, postID).Scan(&count)
+	query := `SELECT COUNT(*) FROM comments WHERE post_id = ? AND status IN (` + placeholders(len(PublicPostStatuses)) + `)`
+	err := s.DB.QueryRow(query, append([]interface{}{postID}, publicStatusParams()...)...).Scan(&count)
 	return count, err
 }
 
 // récupère tous les posts associés à un tag spécifique
 func (s *PostStore) GetPostsByTagID(tagID int64) ([]*Post, error) {
 	query := `
-	SELECT p.id, p.title, p.content, p.user_id, p.image_url, p.like_count, p.dislike_count, p.created_at, p.updated_at 
+	SELECT ` + postColumns + `
 	FROM posts p
 	JOIN post_tags pt ON p.id = pt.post_id
-	WHERE pt.tag_id = ? AND p.status = 'approved'
+	WHERE pt.tag_id = ? AND p.status IN (` + placeholders(len(PublicPostStatuses)) + `)
 	ORDER BY p.created_at DESC
 	`
 
-	rows, err := s.DB.Query(query, tagID)
-	if err != nil {
-		return nil, err
+	params := []interface{}{tagID}
+	for _, status := range PublicPostStatuses {
+		params = append(params, status)
 	}
-	defer rows.Close()
 
-	var posts []*Post
-	for rows.Next() {
-		post := &Post{}
-		err := rows.Scan(
-			&post.ID,
-			&post.Title,
-			&post.Content,
-			&post.UserID,
-			&post.ImageURL,
-			&post.LikeCount,
-			&post.DislikeCount,
-			&post.CreatedAt,
-			&post.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+	return s.queryPosts(query, params...)
+}
+
+// uniqueIDs retire les doublons d'une liste d'identifiants, en conservant l'ordre
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
 		}
-		posts = append(posts, post)
 	}
+	return unique
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+// placeholders retourne une liste de n paramètres SQL ("?, ?, ?")
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
 	}
-
-	return posts, nil
+	return strings.Repeat("?, ", n-1) + "?"
 }
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReportRestoresPreviousStatus(t *testing.T) {
+	db := newTestDB(t)
+	store := NewPostStore(db)
+	author := newTestUser(t, db, "alice")
+
+	post := &Post{UserID: author.ID, Title: "Canaux", Content: "Question", Status: PostStatusActive}
+	if err := store.Create(post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	steps := []struct {
+		name   string
+		apply  func(id int64) error
+		status PostStatus
+	}{
+		{"signalement", store.MarkReported, PostStatusReported},
+		{"second signalement", store.MarkReported, PostStatusReported},
+		{"masquage", store.Hide, PostStatusHidden},
+		{"rétablissement", store.Unhide, PostStatusActive},
+		{"rétablissement sans signalement", store.Unhide, PostStatusActive},
+	}
+
+	for _, step := range steps {
+		if err := step.apply(post.ID); err != nil {
+			t.Fatalf("%s failed: %v", step.name, err)
+		}
+		stored, err := store.GetByID(post.ID)
+		if err != nil {
+			t.Fatalf("%s: failed to read post: %v", step.name, err)
+		}
+		if stored.Status != step.status {
+			t.Errorf("%s: status = %q, want %q", step.name, stored.Status, step.status)
+		}
+	}
+}
+
+func TestActivitySortMixedDateFormats(t *testing.T) {
+	db := newTestDB(t)
+	store := NewPostStore(db)
+	author := newTestUser(t, db, "alice")
+
+	// Dates au format du pilote et au format SQLite : comparées comme chaînes,
+	// "2025-03-28 09:00:00+01:00" (08:00 UTC) passerait après "2025-03-28 08:30:00"
+	dates := []string{"2025-03-28 09:00:00+01:00", "2025-03-28 08:30:00", "2025-03-27 10:00:00"}
+	for i, date := range dates {
+		post := &Post{UserID: author.ID, Title: fmt.Sprintf("Post %d", i), Content: "Question", Status: StatusApproved}
+		if err := store.Create(post); err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+		if _, err := db.Exec("UPDATE posts SET created_at = ? WHERE id = ?", date, post.ID); err != nil {
+			t.Fatalf("failed to date post: %v", err)
+		}
+	}
+
+	filter := PostFilter{SortBy: "activity", SortOrder: "desc", Statuses: PublicPostStatuses}
+	filter.Pagination.PerPage = len(dates)
+	byOffset, err := store.FilterPosts(filter)
+	if err != nil {
+		t.Fatalf("FilterPosts() with offset failed: %v", err)
+	}
+	filter.Cursor = &PostCursor{}
+	byCursor, err := store.FilterPosts(filter)
+	if err != nil {
+		t.Fatalf("FilterPosts() with cursor failed: %v", err)
+	}
+
+	want := []string{"Post 1", "Post 0", "Post 2"}
+	for name, posts := range map[string][]*Post{"offset": byOffset, "cursor": byCursor} {
+		var got []string
+		for _, post := range posts {
+			got = append(got, post.Title)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s pagination order = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestDateFilterMixedDateFormats(t *testing.T) {
+	db := newTestDB(t)
+	store := NewPostStore(db)
+	author := newTestUser(t, db, "alice")
+
+	dates := []string{"2025-03-28 09:00:00+01:00", "2025-03-28 08:30:00", "2025-03-27 10:00:00", "2025-03-29 00:30:00+02:00"}
+	for i, date := range dates {
+		post := &Post{UserID: author.ID, Title: fmt.Sprintf("Post %d", i), Content: "Question", Status: StatusApproved}
+		if err := store.Create(post); err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+		if _, err := db.Exec("UPDATE posts SET created_at = ? WHERE id = ?", date, post.ID); err != nil {
+			t.Fatalf("failed to date post: %v", err)
+		}
+	}
+
+	day := time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want []string
+	}{
+		// "2025-03-28 09:00:00+01:00" vaut 08:00 UTC : avant 08:15 malgré la chaîne
+		{"avant 08:15", time.Time{}, day.Add(8*time.Hour + 15*time.Minute), []string{"Post 0", "Post 2"}},
+		{"après 08:15", day.Add(8*time.Hour + 15*time.Minute), time.Time{}, []string{"Post 1", "Post 3"}},
+		// "2025-03-29 00:30:00+02:00" vaut le 28 à 22:30 UTC
+		{"journée du 28", day, day.AddDate(0, 0, 1).Add(-time.Nanosecond), []string{"Post 0", "Post 1", "Post 3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := PostFilter{SortBy: "date", SortOrder: "asc", Statuses: PublicPostStatuses, DateFrom: tt.from, DateTo: tt.to}
+			filter.Pagination.PerPage = len(dates)
+			posts, err := store.FilterPosts(filter)
+			if err != nil {
+				t.Fatalf("FilterPosts() failed: %v", err)
+			}
+			got := map[string]bool{}
+			for _, post := range posts {
+				got[post.Title] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("FilterPosts() = %v, want %v", got, tt.want)
+			}
+			for _, title := range tt.want {
+				if !got[title] {
+					t.Errorf("FilterPosts() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+			count, err := store.CountPosts(filter)
+			if err != nil {
+				t.Fatalf("CountPosts() failed: %v", err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("CountPosts() = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestGetCommentCountPublicOnly(t *testing.T) {
+	db := newTestDB(t)
+	store := NewPostStore(db)
+	comments := NewCommentStore(db)
+	author := newTestUser(t, db, "alice")
+
+	post := &Post{UserID: author.ID, Title: "Canaux", Content: "Question", Status: StatusApproved}
+	if err := store.Create(post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	statuses := []PostStatus{StatusApproved, PostStatusActive, PostStatusReported, StatusPending, StatusRejected, PostStatusHidden, PostStatusDeleted}
+	for _, status := range statuses {
+		comment := &Comment{PostID: post.ID, UserID: author.ID, Content: string(status), Status: status}
+		if err := comments.Create(comment); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+	}
+
+	count, err := store.GetCommentCount(post.ID)
+	if err != nil {
+		t.Fatalf("GetCommentCount() failed: %v", err)
+	}
+	if count != len(PublicPostStatuses) {
+		t.Errorf("GetCommentCount() = %d, want %d", count, len(PublicPostStatuses))
+	}
+}
+
+func TestTagFiltersMatchCount(t *testing.T) {
+	db := newTestDB(t)
+	store := NewPostStore(db)
+	tagStore := NewTagStore(db)
+	author := newTestUser(t, db, "alice")
+
+	tags := map[string]int64{}
+	for _, name := range []string{"go", "sql", "web"} {
+		tag := &Tag{Name: name}
+		if err := tagStore.Create(tag); err != nil {
+			t.Fatalf("failed to create tag: %v", err)
+		}
+		tags[name] = tag.ID
+	}
+
+	postTags := [][]string{{"go"}, {"go", "sql"}, {"sql"}, {"web"}, {"go", "web"}, {}}
+	for i, names := range postTags {
+		post := &Post{UserID: author.ID, Title: fmt.Sprintf("Post %d", i), Content: "Question", Status: StatusApproved}
+		if err := store.Create(post); err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+		for _, name := range names {
+			if err := store.AddTag(post.ID, tags[name]); err != nil {
+				t.Fatalf("failed to tag post: %v", err)
+			}
+		}
+	}
+
+	ids := func(names ...string) []int64 {
+		var result []int64
+		for _, name := range names {
+			result = append(result, tags[name])
+		}
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		tags    []int64
+		match   string
+		exclude []int64
+		want    []string
+	}{
+		{"un tag", ids("go"), TagMatchAll, nil, []string{"Post 0", "Post 1", "Post 4"}},
+		{"tous les tags", ids("go", "sql"), TagMatchAll, nil, []string{"Post 1"}},
+		{"tag répété", ids("go", "go"), TagMatchAll, nil, []string{"Post 0", "Post 1", "Post 4"}},
+		{"combinaison par défaut", ids("go", "sql"), "", nil, []string{"Post 1"}},
+		{"au moins un tag", ids("go", "sql"), TagMatchAny, nil, []string{"Post 0", "Post 1", "Post 2", "Post 4"}},
+		{"exclusion seule", nil, "", ids("go"), []string{"Post 2", "Post 3", "Post 5"}},
+		{"tag inclus et exclu sur le même post", ids("go"), TagMatchAll, ids("web"), []string{"Post 0", "Post 1"}},
+		{"au moins un tag, avec exclusion", ids("sql", "web"), TagMatchAny, ids("go"), []string{"Post 2", "Post 3"}},
+		{"tag à la fois demandé et exclu", ids("go", "sql"), TagMatchAll, ids("sql"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := PostFilter{SortBy: "title", SortOrder: "asc", Statuses: PublicPostStatuses, Tags: tt.tags, TagMatch: tt.match, ExcludeTags: tt.exclude}
+			filter.Pagination.PerPage = len(postTags)
+
+			count, err := store.CountPosts(filter)
+			if err != nil {
+				t.Fatalf("CountPosts() failed: %v", err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("CountPosts() = %d, want %d", count, len(tt.want))
+			}
+
+			byOffset, err := store.FilterPosts(filter)
+			if err != nil {
+				t.Fatalf("FilterPosts() with offset failed: %v", err)
+			}
+			filter.Cursor = &PostCursor{}
+			byCursor, err := store.FilterPosts(filter)
+			if err != nil {
+				t.Fatalf("FilterPosts() with cursor failed: %v", err)
+			}
+
+			for name, posts := range map[string][]*Post{"offset": byOffset, "cursor": byCursor} {
+				var got []string
+				for _, post := range posts {
+					got = append(got, post.Title)
+				}
+				if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+					t.Errorf("%s pagination = %v, want %v", name, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,472 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Clés des réactions de vote, toujours disponibles : elles alimentent les
+// compteurs like_count et dislike_count utilisés pour les tris
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
+// ReactionType est une réaction proposée aux utilisateurs
+type ReactionType struct {
+	Key   string `json:"key"`
+	Emoji string `json:"emoji"`
+	Label string `json:"label"`
+	// Vote : like et dislike s'excluent, les autres réactions se cumulent
+	Vote bool `json:"vote"`
+}
+
+// ActivityType retourne le type des notifications envoyées pour cette réaction
+func (t ReactionType) ActivityType() ActivityType {
+	switch t.Key {
+	case ReactionLike:
+		return ActivityLike
+	case ReactionDislike:
+		return ActivityDislike
+	default:
+		return ActivityReactionPrefix + ActivityType(t.Key)
+	}
+}
+
+// DefaultReactionTypes sont les réactions connues ; REACTION_TYPES choisit
+// celles qui sont proposées en plus des votes
+var DefaultReactionTypes = []ReactionType{
+	{Key: ReactionLike, Emoji: "👍", Label: "J'aime", Vote: true},
+	{Key: ReactionDislike, Emoji: "👎", Label: "Je n'aime pas", Vote: true},
+	{Key: "helpful", Emoji: "💡", Label: "Utile"},
+	{Key: "same_question", Emoji: "🙋", Label: "Même question"},
+	{Key: "thanks", Emoji: "🙏", Label: "Merci"},
+	{Key: "insightful", Emoji: "🧠", Label: "Éclairant"},
+}
+
+// ReactionRegistry est l'ensemble des réactions actives, dans l'ordre d'affichage
+type ReactionRegistry struct {
+	types []ReactionType
+	byKey map[string]ReactionType
+}
+
+// NewReactionRegistry crée un registre ; like et dislike y sont toujours ajoutés
+func NewReactionRegistry(types []ReactionType) *ReactionRegistry {
+	registry := &ReactionRegistry{byKey: make(map[string]ReactionType)}
+	registry.add(DefaultReactionTypes[0])
+	registry.add(DefaultReactionTypes[1])
+	for _, t := range types {
+		registry.add(t)
+	}
+	return registry
+}
+
+func (r *ReactionRegistry) add(t ReactionType) {
+	if _, exists := r.byKey[t.Key]; exists {
+		return
+	}
+	r.types = append(r.types, t)
+	r.byKey[t.Key] = t
+}
+
+// LoadReactionRegistry lit la liste des réactions dans la variable
+// d'environnement REACTION_TYPES, séparées par des virgules. Chaque entrée est
+// la clé d'une réaction connue (helpful) ou une définition clé:emoji:libellé.
+// Sans REACTION_TYPES, toutes les réactions connues sont proposées.
+func LoadReactionRegistry() *ReactionRegistry {
+	value := os.Getenv("REACTION_TYPES")
+	if value == "" {
+		return NewReactionRegistry(DefaultReactionTypes)
+	}
+
+	known := make(map[string]ReactionType, len(DefaultReactionTypes))
+	for _, t := range DefaultReactionTypes {
+		known[t.Key] = t
+	}
+
+	var types []ReactionType
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) == 1 {
+			if t, ok := known[entry]; ok {
+				types = append(types, t)
+			} else {
+				log.Printf("Réaction inconnue %q ignorée (format attendu : clé:emoji:libellé)", entry)
+			}
+			continue
+		}
+		if len(parts) != 3 || !validReactionKey(parts[0]) || parts[1] == "" || parts[2] == "" {
+			log.Printf("Définition de réaction invalide %q ignorée", entry)
+			continue
+		}
+		types = append(types, ReactionType{Key: parts[0], Emoji: parts[1], Label: strings.TrimSpace(parts[2])})
+	}
+	return NewReactionRegistry(types)
+}
+
+// validReactionKey accepte les clés en minuscules, chiffres et soulignés
+func validReactionKey(key string) bool {
+	if key == "" || len(key) > 32 {
+		return false
+	}
+	for _, c := range key {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// Types retourne toutes les réactions actives
+func (r *ReactionRegistry) Types() []ReactionType {
+	return r.types
+}
+
+// Extra retourne les réactions actives autres que les votes
+func (r *ReactionRegistry) Extra() []ReactionType {
+	var extra []ReactionType
+	for _, t := range r.types {
+		if !t.Vote {
+			extra = append(extra, t)
+		}
+	}
+	return extra
+}
+
+// Get retourne la réaction de clé donnée si elle est active
+func (r *ReactionRegistry) Get(key string) (ReactionType, bool) {
+	t, ok := r.byKey[key]
+	return t, ok
+}
+
+// Reaction est la réaction d'un utilisateur à un post ou un commentaire
+type Reaction struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id,omitempty"`
+	CommentID int64     `json:"comment_id,omitempty"`
+	UserID    int64     `json:"user_id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionCounts associe à chaque type de réaction son nombre d'occurrences
+type ReactionCounts map[string]int
+
+// Likes retourne le nombre de likes
+func (c ReactionCounts) Likes() int {
+	return c[ReactionLike]
+}
+
+// Dislikes retourne le nombre de dislikes
+func (c ReactionCounts) Dislikes() int {
+	return c[ReactionDislike]
+}
+
+// UserReactions est l'ensemble des réactions posées par un utilisateur sur une cible
+type UserReactions map[string]bool
+
+// Vote retourne le vote de l'utilisateur (like, dislike) ou une chaîne vide
+func (u UserReactions) Vote() string {
+	switch {
+	case u[ReactionLike]:
+		return ReactionLike
+	case u[ReactionDislike]:
+		return ReactionDislike
+	default:
+		return ""
+	}
+}
+
+// ReactionTarget désigne la table réagie et la colonne qui la référence dans reactions
+type ReactionTarget struct {
+	table  string
+	column string
+}
+
+var (
+	PostReactions    = ReactionTarget{table: "posts", column: "post_id"}
+	CommentReactions = ReactionTarget{table: "comments", column: "comment_id"}
+)
+
+type ReactionStore struct {
+	DB       *sql.DB
+	Registry *ReactionRegistry
+}
+
+func NewReactionStore(db *sql.DB, registry *ReactionRegistry) *ReactionStore {
+	return &ReactionStore{DB: db, Registry: registry}
+}
+
+// Toggle ajoute la réaction de l'utilisateur, ou la retire s'il l'avait déjà
+// posée. Ajouter un vote remplace le vote opposé. Retourne les compteurs de la
+// cible et indique si la réaction est désormais posée.
+func (s *ReactionStore) Toggle(target ReactionTarget, targetID, userID int64, reaction ReactionType) (ReactionCounts, bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM reactions WHERE "+target.column+" = ? AND user_id = ? AND type = ?",
+		targetID, userID, reaction.Key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	removed, _ := result.RowsAffected()
+
+	if removed == 0 {
+		if reaction.Vote {
+			_, err = tx.Exec("DELETE FROM reactions WHERE "+target.column+" = ? AND user_id = ? AND type IN (?, ?)",
+				targetID, userID, ReactionLike, ReactionDislike)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to replace vote: %w", err)
+			}
+		}
+		_, err = tx.Exec("INSERT INTO reactions ("+target.column+", user_id, type, created_at) VALUES (?, ?, ?, ?)",
+			targetID, userID, reaction.Key, time.Now())
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to save reaction: %w", err)
+		}
+	}
+
+	counts, err := s.recount(tx, target, targetID)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit reaction: %w", err)
+	}
+	return counts, removed == 0, nil
+}
+
+// ClearVote retire le like ou le dislike de l'utilisateur ; sans vote, rien n'est modifié
+func (s *ReactionStore) ClearVote(target ReactionTarget, targetID, userID int64) (ReactionCounts, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM reactions WHERE "+target.column+" = ? AND user_id = ? AND type IN (?, ?)",
+		targetID, userID, ReactionLike, ReactionDislike)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove vote: %w", err)
+	}
+
+	counts, err := s.recount(tx, target, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reaction: %w", err)
+	}
+	return counts, nil
+}
+
+// recount recalcule les compteurs de vote de la cible à partir de reactions,
+// plutôt que de les incrémenter : des requêtes simultanées ne peuvent pas les
+// faire dériver. Retourne les compteurs de tous les types.
+func (s *ReactionStore) recount(tx *sql.Tx, target ReactionTarget, targetID int64) (ReactionCounts, error) {
+	_, err := tx.Exec(`UPDATE `+target.table+` SET
+			like_count = (SELECT COUNT(*) FROM reactions WHERE `+target.column+` = ? AND type = ?),
+			dislike_count = (SELECT COUNT(*) FROM reactions WHERE `+target.column+` = ? AND type = ?)
+		WHERE id = ?`,
+		targetID, ReactionLike, targetID, ReactionDislike, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reaction counts: %w", err)
+	}
+
+	rows, err := tx.Query("SELECT type, COUNT(*) FROM reactions WHERE "+target.column+" = ? GROUP BY type", targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(ReactionCounts)
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction count: %w", err)
+		}
+		counts[key] = count
+	}
+	return counts, rows.Err()
+}
+
+// Counts retourne les compteurs par type de réaction d'un post ou d'un commentaire
+func (s *ReactionStore) Counts(target ReactionTarget, targetID int64) (ReactionCounts, error) {
+	all, err := s.CountsFor(target, []int64{targetID})
+	if err != nil {
+		return nil, err
+	}
+	if counts, ok := all[targetID]; ok {
+		return counts, nil
+	}
+	return make(ReactionCounts), nil
+}
+
+// CountsFor retourne les compteurs par type de plusieurs posts ou commentaires
+func (s *ReactionStore) CountsFor(target ReactionTarget, targetIDs []int64) (map[int64]ReactionCounts, error) {
+	all := make(map[int64]ReactionCounts, len(targetIDs))
+	if len(targetIDs) == 0 {
+		return all, nil
+	}
+
+	args := make([]interface{}, len(targetIDs))
+	for i, id := range targetIDs {
+		args[i] = id
+	}
+	rows, err := s.DB.Query("SELECT "+target.column+", type, COUNT(*) FROM reactions WHERE "+target.column+
+		" IN ("+placeholders(len(targetIDs))+") GROUP BY "+target.column+", type", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var key string
+		var count int
+		if err := rows.Scan(&id, &key, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction count: %w", err)
+		}
+		if all[id] == nil {
+			all[id] = make(ReactionCounts)
+		}
+		all[id][key] = count
+	}
+	return all, rows.Err()
+}
+
+// UserReactions retourne les réactions posées par un utilisateur sur une cible
+func (s *ReactionStore) UserReactions(target ReactionTarget, targetID, userID int64) (UserReactions, error) {
+	rows, err := s.DB.Query("SELECT type FROM reactions WHERE "+target.column+" = ? AND user_id = ?", targetID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user reactions: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := make(UserReactions)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan user reaction: %w", err)
+		}
+		reactions[key] = true
+	}
+	return reactions, rows.Err()
+}
+
+// UserCommentReactions retourne les réactions d'un utilisateur aux commentaires d'un post
+func (s *ReactionStore) UserCommentReactions(postID, userID int64) (map[int64]UserReactions, error) {
+	rows, err := s.DB.Query(`SELECT r.comment_id, r.type FROM reactions r
+		JOIN comments c ON c.id = r.comment_id
+		WHERE c.post_id = ? AND r.user_id = ?`, postID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user comment reactions: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := make(map[int64]UserReactions)
+	for rows.Next() {
+		var commentID int64
+		var key string
+		if err := rows.Scan(&commentID, &key); err != nil {
+			return nil, fmt.Errorf("failed to scan user reaction: %w", err)
+		}
+		if reactions[commentID] == nil {
+			reactions[commentID] = make(UserReactions)
+		}
+		reactions[commentID][key] = true
+	}
+	return reactions, rows.Err()
+}
+
+// Reactor est un utilisateur ayant posé une réaction
+type Reactor struct {
+	UserID    int64     `json:"id"`
+	Username  string    `json:"username"`
+	AvatarURL string    `json:"avatar_url"`
+	ReactedAt time.Time `json:"reacted_at"`
+}
+
+// ReactorPage est une page de la liste des utilisateurs ayant posé une réaction
+type ReactorPage struct {
+	Reactors []*Reactor
+	// Total compte toutes les réactions de ce type, anonymes comprises
+	Total int
+	// Anonymous compte les dislikes des utilisateurs qui les gardent anonymes
+	Anonymous int
+}
+
+// Reactors liste les utilisateurs ayant posé la réaction key sur une cible,
+// du plus récent au plus ancien. Les dislikes des utilisateurs ayant choisi
+// l'anonymat sont seulement comptés.
+func (s *ReactionStore) Reactors(target ReactionTarget, targetID int64, key string, pagination PaginationParams) (*ReactorPage, error) {
+	page := &ReactorPage{}
+	err := s.DB.QueryRow(`SELECT COUNT(*), COALESCE(SUM(r.type = ? AND u.anonymous_dislikes), 0)
+		FROM reactions r JOIN users u ON u.id = r.user_id
+		WHERE r.`+target.column+` = ? AND r.type = ?`,
+		ReactionDislike, targetID, key).Scan(&page.Total, &page.Anonymous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactors: %w", err)
+	}
+
+	rows, err := s.DB.Query(`SELECT u.id, u.username, u.avatar_url, r.created_at
+		FROM reactions r JOIN users u ON u.id = r.user_id
+		WHERE r.`+target.column+` = ? AND r.type = ? AND NOT (r.type = ? AND u.anonymous_dislikes)
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT ? OFFSET ?`,
+		targetID, key, ReactionDislike, pagination.PerPage, (pagination.Page-1)*pagination.PerPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reactors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reactor Reactor
+		if err := rows.Scan(&reactor.UserID, &reactor.Username, &reactor.AvatarURL, &reactor.ReactedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reactor: %w", err)
+		}
+		page.Reactors = append(page.Reactors, &reactor)
+	}
+	return page, rows.Err()
+}
+
+// GetLikedPostIDs récupère les IDs de tous les posts qu'un utilisateur a aimés
+func (s *ReactionStore) GetLikedPostIDs(userID int64) ([]int64, error) {
+	query := `
+		SELECT post_id FROM reactions
+		WHERE user_id = ? AND post_id IS NOT NULL AND type = ?
+	`
+
+	rows, err := s.DB.Query(query, userID, ReactionLike)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postIDs []int64
+	for rows.Next() {
+		var postID int64
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return postIDs, nil
+}
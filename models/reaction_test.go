@@ -0,0 +1,118 @@
+package models
+
+import (
+	"database/sql"
+	"forum/database"
+	"testing"
+)
+
+func TestReactionToggle(t *testing.T) {
+	db := newTestDB(t)
+	registry := NewReactionRegistry(DefaultReactionTypes)
+	store := NewReactionStore(db, registry)
+	author := newTestUser(t, db, "alice")
+	reader := newTestUser(t, db, "bob")
+
+	post := &Post{UserID: author.ID, Title: "Canaux", Content: "Question", Status: StatusApproved}
+	if err := NewPostStore(db).Create(post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	like, _ := registry.Get(ReactionLike)
+	dislike, _ := registry.Get(ReactionDislike)
+	helpful, _ := registry.Get("helpful")
+
+	steps := []struct {
+		name         string
+		reaction     ReactionType
+		wantAdded    bool
+		wantLikes    int
+		wantDislikes int
+		wantHelpful  int
+	}{
+		{"like", like, true, 1, 0, 0},
+		{"réaction en plus du vote", helpful, true, 1, 0, 1},
+		{"le dislike remplace le like", dislike, true, 0, 1, 1},
+		{"second dislike : retrait", dislike, false, 0, 0, 1},
+		{"retrait de la réaction", helpful, false, 0, 0, 0},
+	}
+
+	for _, step := range steps {
+		counts, added, err := store.Toggle(PostReactions, post.ID, reader.ID, step.reaction)
+		if err != nil {
+			t.Fatalf("%s: Toggle() failed: %v", step.name, err)
+		}
+		if added != step.wantAdded {
+			t.Errorf("%s: added = %v, want %v", step.name, added, step.wantAdded)
+		}
+		if counts.Likes() != step.wantLikes || counts.Dislikes() != step.wantDislikes || counts["helpful"] != step.wantHelpful {
+			t.Errorf("%s: counts = %v, want %d likes, %d dislikes, %d helpful",
+				step.name, counts, step.wantLikes, step.wantDislikes, step.wantHelpful)
+		}
+
+		stored, err := NewPostStore(db).GetByID(post.ID)
+		if err != nil {
+			t.Fatalf("%s: failed to read post: %v", step.name, err)
+		}
+		if stored.LikeCount != step.wantLikes || stored.DislikeCount != step.wantDislikes {
+			t.Errorf("%s: post counters = %d/%d, want %d/%d",
+				step.name, stored.LikeCount, stored.DislikeCount, step.wantLikes, step.wantDislikes)
+		}
+	}
+}
+
+func TestRecountReactions(t *testing.T) {
+	db := newTestDB(t)
+	store := NewReactionStore(db, NewReactionRegistry(nil))
+	author := newTestUser(t, db, "alice")
+	reader := newTestUser(t, db, "bob")
+
+	post := &Post{UserID: author.ID, Title: "Canaux", Content: "Question", Status: StatusApproved}
+	if err := NewPostStore(db).Create(post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	like, _ := store.Registry.Get(ReactionLike)
+	if _, _, err := store.Toggle(PostReactions, post.ID, reader.ID, like); err != nil {
+		t.Fatalf("Toggle() failed: %v", err)
+	}
+
+	// Compteurs faussés, comme par l'ancien code qui les incrémentait
+	if _, err := db.Exec("UPDATE posts SET like_count = 5, dislike_count = 2 WHERE id = ?", post.ID); err != nil {
+		t.Fatalf("failed to corrupt counters: %v", err)
+	}
+
+	posts, comments, err := database.RecountReactions(db)
+	if err != nil {
+		t.Fatalf("RecountReactions() failed: %v", err)
+	}
+	if posts != 1 || comments != 0 {
+		t.Errorf("RecountReactions() fixed %d posts and %d comments, want 1 and 0", posts, comments)
+	}
+
+	stored, err := NewPostStore(db).GetByID(post.ID)
+	if err != nil {
+		t.Fatalf("failed to read post: %v", err)
+	}
+	if stored.LikeCount != 1 || stored.DislikeCount != 0 {
+		t.Errorf("post counters = %d/%d after recount, want 1/0", stored.LikeCount, stored.DislikeCount)
+	}
+
+	if posts, _, err := database.RecountReactions(db); err != nil || posts != 0 {
+		t.Errorf("second RecountReactions() = %d, %v, want 0, nil", posts, err)
+	}
+
+	// Les colonnes acceptent NULL : ces compteurs sont réparés aussi
+	if _, err := db.Exec("UPDATE posts SET like_count = NULL, dislike_count = NULL WHERE id = ?", post.ID); err != nil {
+		t.Fatalf("failed to corrupt counters: %v", err)
+	}
+	if posts, _, err := database.RecountReactions(db); err != nil || posts != 1 {
+		t.Errorf("RecountReactions() with NULL counters = %d, %v, want 1, nil", posts, err)
+	}
+	var likes, dislikes sql.NullInt64
+	if err := db.QueryRow("SELECT like_count, dislike_count FROM posts WHERE id = ?", post.ID).Scan(&likes, &dislikes); err != nil {
+		t.Fatalf("failed to read counters: %v", err)
+	}
+	if likes.Int64 != 1 || !dislikes.Valid || dislikes.Int64 != 0 {
+		t.Errorf("post counters = %v/%v after recount, want 1/0", likes, dislikes)
+	}
+}
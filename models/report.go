@@ -0,0 +1,217 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ReportReason correspond à la colonne INTEGER reports.reason
+type ReportReason int
+
+const (
+	ReasonSpam ReportReason = iota + 1
+	ReasonAbuse
+	ReasonHateSpeech
+	ReasonOther
+)
+
+// Label retourne le libellé affiché pour une raison de signalement
+func (r ReportReason) Label() string {
+	switch r {
+	case ReasonSpam:
+		return "Spam"
+	case ReasonAbuse:
+		return "Contenu abusif"
+	case ReasonHateSpeech:
+		return "Discours haineux"
+	default:
+		return "Autre"
+	}
+}
+
+// IsValid indique si la raison fait partie des valeurs connues
+func (r ReportReason) IsValid() bool {
+	return r >= ReasonSpam && r <= ReasonOther
+}
+
+type ReportStatus string
+
+const (
+	ReportPending   ReportStatus = "pending"
+	ReportResolved  ReportStatus = "resolved"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// Report représente un signalement de post ou de commentaire
+type Report struct {
+	ID             int64        `json:"id"`
+	PostID         int64        `json:"post_id"`
+	CommentID      int64        `json:"comment_id,omitempty"`
+	UserID         int64        `json:"user_id"`
+	ModeratorID    int64        `json:"moderator_id,omitempty"`
+	Reason         ReportReason `json:"reason"`
+	Description    string       `json:"description"`
+	Status         ReportStatus `json:"status"`
+	CreatedAt      time.Time    `json:"created_at"`
+	ResolvedAt     time.Time    `json:"resolved_at"`
+	ResolutionNote string       `json:"resolution_note"`
+}
+
+// ReportStore gère les opérations sur les signalements
+type ReportStore struct {
+	DB *sql.DB
+}
+
+// NewReportStore crée une nouvelle instance de ReportStore
+func NewReportStore(db *sql.DB) *ReportStore {
+	return &ReportStore{DB: db}
+}
+
+const reportColumns = `id, post_id, COALESCE(comment_id, 0), user_id, COALESCE(moderator_id, 0), reason,
+	COALESCE(description, ''), COALESCE(status, 'pending'), created_at, resolved_at, COALESCE(resolution_note, '')`
+
+func scanReport(scanner interface{ Scan(...interface{}) error }) (*Report, error) {
+	var report Report
+	var resolvedAt sql.NullTime
+	err := scanner.Scan(
+		&report.ID,
+		&report.PostID,
+		&report.CommentID,
+		&report.UserID,
+		&report.ModeratorID,
+		&report.Reason,
+		&report.Description,
+		&report.Status,
+		&report.CreatedAt,
+		&resolvedAt,
+		&report.ResolutionNote,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		report.ResolvedAt = resolvedAt.Time
+	}
+	return &report, nil
+}
+
+func (s *ReportStore) queryReports(query string, args ...interface{}) ([]*Report, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// Create enregistre un nouveau signalement
+func (s *ReportStore) Create(report *Report) error {
+	query := `
+		INSERT INTO reports (post_id, comment_id, user_id, reason, description, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	var commentID interface{}
+	if report.CommentID != 0 {
+		commentID = report.CommentID
+	}
+
+	report.Status = ReportPending
+	report.CreatedAt = time.Now()
+
+	return s.DB.QueryRow(
+		query,
+		report.PostID,
+		commentID,
+		report.UserID,
+		report.Reason,
+		report.Description,
+		report.Status,
+		report.CreatedAt,
+	).Scan(&report.ID)
+}
+
+// GetByID récupère un signalement par son ID
+func (s *ReportStore) GetByID(id int64) (*Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = ?`
+	return scanReport(s.DB.QueryRow(query, id))
+}
+
+// GetPending récupère les signalements en attente, les plus anciens d'abord
+func (s *ReportStore) GetPending() ([]*Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE status = ? ORDER BY created_at ASC`
+	return s.queryReports(query, ReportPending)
+}
+
+// GetRecentlyResolved récupère les derniers signalements traités
+func (s *ReportStore) GetRecentlyResolved(limit int) ([]*Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE status != ? ORDER BY resolved_at DESC LIMIT ?`
+	return s.queryReports(query, ReportPending, limit)
+}
+
+// GetPendingForTarget récupère les signalements en attente sur un post ou un commentaire
+func (s *ReportStore) GetPendingForTarget(postID, commentID int64) ([]*Report, error) {
+	if commentID != 0 {
+		query := `SELECT ` + reportColumns + ` FROM reports WHERE comment_id = ? AND status = ?`
+		return s.queryReports(query, commentID, ReportPending)
+	}
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE post_id = ? AND comment_id IS NULL AND status = ?`
+	return s.queryReports(query, postID, ReportPending)
+}
+
+// HasPendingReport indique si l'utilisateur a déjà un signalement en attente sur la cible
+func (s *ReportStore) HasPendingReport(userID, postID, commentID int64) (bool, error) {
+	reports, err := s.GetPendingForTarget(postID, commentID)
+	if err != nil {
+		return false, err
+	}
+	for _, report := range reports {
+		if report.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Resolve clôture un signalement avec une note de résolution
+func (s *ReportStore) Resolve(id, moderatorID int64, status ReportStatus, note string) error {
+	query := `
+		UPDATE reports
+		SET status = ?, moderator_id = ?, resolution_note = ?, resolved_at = ?
+		WHERE id = ?
+	`
+	_, err := s.DB.Exec(query, status, moderatorID, note, time.Now(), id)
+	return err
+}
+
+// CountPending compte les signalements en attente
+func (s *ReportStore) CountPending() (int, error) {
+	var count int
+	err := s.DB.QueryRow("SELECT COUNT(*) FROM reports WHERE status = ?", ReportPending).Scan(&count)
+	return count, err
+}
+
+// IsCommentReport indique si le signalement concerne un commentaire
+func (r *Report) IsCommentReport() bool {
+	return r.CommentID != 0
+}
+
+// GetFormattedDate retourne la date formatée
+func (r *Report) GetFormattedDate() string {
+	return r.CreatedAt.Format("02 Jan 2006 à 15:04")
+}
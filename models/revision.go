@@ -0,0 +1,169 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PostRevision est une version d'un post, conservée à chaque modification
+type PostRevision struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	Revision  int       `json:"revision"`
+	UserID    int64     `json:"user_id"` // Auteur de cette version
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RevisionStore gère l'historique des posts
+type RevisionStore struct {
+	DB *sql.DB
+}
+
+// NewRevisionStore crée une nouvelle instance de RevisionStore
+func NewRevisionStore(db *sql.DB) *RevisionStore {
+	return &RevisionStore{DB: db}
+}
+
+const revisionColumns = `id, post_id, revision, user_id, title, content, created_at`
+
+func scanRevision(scanner interface{ Scan(...interface{}) error }) (*PostRevision, error) {
+	var rev PostRevision
+	err := scanner.Scan(
+		&rev.ID,
+		&rev.PostID,
+		&rev.Revision,
+		&rev.UserID,
+		&rev.Title,
+		&rev.Content,
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// recordRevision enregistre la nouvelle version d'un post modifié par editorID.
+// À la première modification, la version d'origine est d'abord conservée.
+func recordRevision(tx *sql.Tx, before, after *Post, editorID int64) error {
+	var last int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(revision), 0) FROM post_revisions WHERE post_id = ?", before.ID).Scan(&last); err != nil {
+		return fmt.Errorf("failed to read revisions: %w", err)
+	}
+
+	insert := `INSERT INTO post_revisions (post_id, revision, user_id, title, content, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	if last == 0 {
+		last++
+		if _, err := tx.Exec(insert, before.ID, last, before.UserID, before.Title, before.Content, before.CreatedAt); err != nil {
+			return fmt.Errorf("failed to record original revision: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(insert, after.ID, last+1, editorID, after.Title, after.Content, time.Now()); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+	return nil
+}
+
+// GetByPostID retourne les versions d'un post, de la plus ancienne à la plus récente
+func (s *RevisionStore) GetByPostID(postID int64) ([]*PostRevision, error) {
+	rows, err := s.DB.Query(`SELECT `+revisionColumns+` FROM post_revisions WHERE post_id = ? ORDER BY revision`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*PostRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision retourne une version précise d'un post
+func (s *RevisionStore) GetRevision(postID int64, revision int) (*PostRevision, error) {
+	row := s.DB.QueryRow(`SELECT `+revisionColumns+` FROM post_revisions WHERE post_id = ? AND revision = ?`, postID, revision)
+	return scanRevision(row)
+}
+
+// Latest retourne la dernière version d'un post, ou nil s'il n'a jamais été modifié
+func (s *RevisionStore) Latest(postID int64) (*PostRevision, error) {
+	row := s.DB.QueryRow(`SELECT `+revisionColumns+` FROM post_revisions WHERE post_id = ? ORDER BY revision DESC LIMIT 1`, postID)
+	rev, err := scanRevision(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rev, err
+}
+
+// DiffKind indique si une ligne est commune, ajoutée ou supprimée
+type DiffKind string
+
+const (
+	DiffEqual   DiffKind = "equal"
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+)
+
+// DiffLine est une ligne du différentiel entre deux versions
+type DiffLine struct {
+	Kind DiffKind
+	Text string
+}
+
+// DiffLines calcule le différentiel ligne à ligne entre deux textes
+// (plus longue sous-séquence commune)
+func DiffLines(before, after string) []DiffLine {
+	a := strings.Split(strings.ReplaceAll(before, "\r\n", "\n"), "\n")
+	b := strings.Split(strings.ReplaceAll(after, "\r\n", "\n"), "\n")
+
+	// lcs[i][j] : longueur de la plus longue sous-séquence commune de a[i:] et b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Kind: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Kind: DiffRemoved, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Kind: DiffAdded, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Kind: DiffRemoved, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Kind: DiffAdded, Text: b[j]})
+	}
+
+	return diff
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []DiffLine
+	}{
+		{
+			name:   "textes identiques",
+			before: "a\nb",
+			after:  "a\nb",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffEqual, "b"}},
+		},
+		{
+			name:   "ligne ajoutée",
+			before: "a\nc",
+			after:  "a\nb\nc",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffAdded, "b"}, {DiffEqual, "c"}},
+		},
+		{
+			name:   "ligne supprimée",
+			before: "a\nb\nc",
+			after:  "a\nc",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffRemoved, "b"}, {DiffEqual, "c"}},
+		},
+		{
+			name:   "ligne modifiée",
+			before: "a\nb\nc",
+			after:  "a\nB\nc",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffRemoved, "b"}, {DiffAdded, "B"}, {DiffEqual, "c"}},
+		},
+		{
+			name:   "fins de ligne Windows",
+			before: "a\r\nb",
+			after:  "a\nb",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffEqual, "b"}},
+		},
+		{
+			name:   "texte vidé",
+			before: "a\nb",
+			after:  "",
+			want:   []DiffLine{{DiffRemoved, "a"}, {DiffRemoved, "b"}, {DiffAdded, ""}},
+		},
+		{
+			name:   "ajouts en fin de texte",
+			before: "a",
+			after:  "a\nb\nc",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffAdded, "b"}, {DiffAdded, "c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines(%q, %q) = %v, want %v", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateWithRevision(t *testing.T) {
+	db := newTestDB(t)
+	store := NewPostStore(db)
+	revisions := NewRevisionStore(db)
+	author := newTestUser(t, db, "alice")
+
+	post := &Post{UserID: author.ID, Title: "Canaux", Content: "Question", Status: StatusApproved}
+	if err := store.Create(post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	before := *post
+	post.Content = "Question précisée"
+	if err := store.UpdateWithRevision(&before, post, author.ID); err != nil {
+		t.Fatalf("UpdateWithRevision() failed: %v", err)
+	}
+	history, err := revisions.GetByPostID(post.ID)
+	if err != nil {
+		t.Fatalf("failed to read revisions: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != "Question" || history[1].Content != "Question précisée" {
+		t.Fatalf("revisions after first edit = %+v, want the original and the edit", history)
+	}
+
+	// Si la version ne peut pas être enregistrée, la modification est annulée
+	if _, err := db.Exec(`CREATE TRIGGER fail_revision BEFORE INSERT ON post_revisions
+		BEGIN SELECT RAISE(ABORT, 'revision refused'); END`); err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+	before = *post
+	post.Content = "Question réécrite"
+	if err := store.UpdateWithRevision(&before, post, author.ID); err == nil {
+		t.Fatal("UpdateWithRevision() succeeded, want an error")
+	}
+	stored, err := store.GetByID(post.ID)
+	if err != nil {
+		t.Fatalf("failed to read post: %v", err)
+	}
+	if stored.Content != "Question précisée" {
+		t.Errorf("content after failed edit = %q, want %q", stored.Content, "Question précisée")
+	}
+}
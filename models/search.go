@@ -0,0 +1,115 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Marqueurs entourant les termes trouvés dans les extraits de recherche.
+// Ce sont des caractères de contrôle : l'extrait est échappé avant qu'ils
+// soient remplacés par des balises <mark>.
+const (
+	SnippetMatchStart = "\x02"
+	SnippetMatchEnd   = "\x03"
+)
+
+// FullTextSearch indique si la recherche utilise les index FTS5. Sans eux
+// (SQLite compilé sans FTS5), elle se replie sur LIKE, sans extrait ni
+// classement par pertinence.
+var FullTextSearch = true
+
+// fullTextMatch retourne l'expression MATCH de la recherche, ou une chaîne
+// vide si la recherche plein texte n'est pas disponible
+func fullTextMatch(input string) string {
+	if !FullTextSearch {
+		return ""
+	}
+	return ParseSearchQuery(input)
+}
+
+// searchTerm est un mot ou une phrase de la recherche saisie par l'utilisateur
+type searchTerm struct {
+	text    string
+	prefix  bool // mot* : tous les mots commençant par text
+	exclude bool // -mot : les résultats ne doivent pas le contenir
+}
+
+// ParseSearchQuery traduit une recherche en expression MATCH FTS5.
+// Syntaxe acceptée : mots (tous requis), "phrase exacte", préfixe* et -exclusion.
+// Chaque terme est cité : les caractères spéciaux de FTS5 saisis par
+// l'utilisateur ne peuvent pas produire d'erreur de syntaxe. Retourne une
+// chaîne vide si la recherche ne contient aucun terme à trouver.
+func ParseSearchQuery(input string) string {
+	var included, excluded []string
+	for _, term := range splitSearchTerms(input) {
+		expr := `"` + strings.ReplaceAll(term.text, `"`, `""`) + `"`
+		if term.prefix {
+			expr += "*"
+		}
+		if term.exclude {
+			excluded = append(excluded, expr)
+		} else {
+			included = append(included, expr)
+		}
+	}
+
+	// FTS5 ne sait pas exclure sans terme à trouver
+	if len(included) == 0 {
+		return ""
+	}
+
+	query := "(" + strings.Join(included, " ") + ")"
+	for _, expr := range excluded {
+		query += " NOT " + expr
+	}
+	return query
+}
+
+// splitSearchTerms découpe la recherche en mots et phrases entre guillemets
+func splitSearchTerms(input string) []searchTerm {
+	var terms []searchTerm
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		var term searchTerm
+		if runes[i] == '-' {
+			term.exclude = true
+			i++
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			// Phrase : jusqu'au guillemet fermant ou la fin de la saisie
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			term.text = strings.Join(strings.Fields(string(runes[i+1:end])), " ")
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+			if strings.HasSuffix(word, "*") {
+				term.prefix = true
+				word = strings.TrimRight(word, "*")
+			}
+			term.text = word
+		}
+
+		// Un terme sans lettre ni chiffre ne correspond à aucun mot indexé
+		if strings.IndexFunc(term.text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) < 0 {
+			continue
+		}
+		terms = append(terms, term)
+	}
+
+	return terms
+}
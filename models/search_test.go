@@ -0,0 +1,42 @@
+package models
+
+import "testing"
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"mot seul", "goroutine", `("goroutine")`},
+		{"plusieurs mots", "go   canaux", `("go" "canaux")`},
+		{"phrase exacte", `"phrase   exacte"`, `("phrase exacte")`},
+		{"préfixe", "gorout*", `("gorout"*)`},
+		{"exclusion", "python -java", `("python") NOT "java"`},
+		{"phrase exclue", `-"mauvaise idée" bonne`, `("bonne") NOT "mauvaise idée"`},
+		{"guillemet non fermé", `dire "bonjour`, `("dire" "bonjour")`},
+		{"guillemet dans un mot", `a"b`, `("a" "b")`},
+		{"caractères spéciaux FTS5", "c++ AND (x)", `("c++" "AND" "(x)")`},
+		{"accents conservés", "modèle*", `("modèle"*)`},
+		{"exclusions seules", "-java -python", ""},
+		{"ponctuation seule", "!!! ... **", ""},
+		{"vide", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSearchQuery(tt.input); got != tt.want {
+				t.Errorf("ParseSearchQuery(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFullTextMatchDisabled(t *testing.T) {
+	defer func(previous bool) { FullTextSearch = previous }(FullTextSearch)
+
+	FullTextSearch = false
+	if got := fullTextMatch("goroutine"); got != "" {
+		t.Errorf("fullTextMatch without FTS5 = %q, want empty", got)
+	}
+}
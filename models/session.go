@@ -0,0 +1,224 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// Durée de vie d'une session et seuil de renouvellement
+const (
+	SessionDuration      = 24 * time.Hour
+	SessionRenewAfter    = SessionDuration / 2
+	sessionTouchInterval = time.Minute
+)
+
+// Session représente une connexion active d'un utilisateur
+type Session struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	TokenHash  string    `json:"-"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// SessionStore gère les sessions côté serveur
+type SessionStore struct {
+	DB *sql.DB
+}
+
+// NewSessionStore crée une nouvelle instance de SessionStore
+func NewSessionStore(db *sql.DB) *SessionStore {
+	return &SessionStore{DB: db}
+}
+
+// hashSessionToken retourne l'empreinte stockée en base pour un jeton
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateSessionToken génère un jeton opaque aléatoire
+func generateSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Create ouvre une nouvelle session et retourne le jeton à placer dans le cookie.
+// Seule l'empreinte du jeton est conservée en base.
+func (s *SessionStore) Create(userID int64, ipAddress, userAgent string) (*Session, string, error) {
+	token, err := generateSessionToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := &Session{
+		UserID:     userID,
+		TokenHash:  hashSessionToken(token),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(SessionDuration),
+	}
+
+	query := `
+		INSERT INTO sessions (user_id, token_hash, ip_address, user_agent, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err = s.DB.QueryRow(
+		query,
+		session.UserID,
+		session.TokenHash,
+		session.IPAddress,
+		session.UserAgent,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	).Scan(&session.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, token, nil
+}
+
+// GetByToken récupère une session non expirée à partir du jeton du cookie
+func (s *SessionStore) GetByToken(token string) (*Session, error) {
+	query := `SELECT id, user_id, token_hash, ip_address, user_agent, created_at, last_seen_at, expires_at
+              FROM sessions WHERE token_hash = ? AND expires_at > ?`
+
+	var session Session
+	err := s.DB.QueryRow(query, hashSessionToken(token), time.Now()).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.IPAddress,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Touch met à jour la date de dernière activité et prolonge la session
+// lorsqu'elle a dépassé la moitié de sa durée de vie.
+// Retourne true si l'expiration a été repoussée.
+func (s *SessionStore) Touch(session *Session) (bool, error) {
+	now := time.Now()
+	renewed := session.ExpiresAt.Sub(now) < SessionDuration-SessionRenewAfter
+
+	if !renewed && now.Sub(session.LastSeenAt) < sessionTouchInterval {
+		return false, nil
+	}
+
+	session.LastSeenAt = now
+	if renewed {
+		session.ExpiresAt = now.Add(SessionDuration)
+	}
+
+	_, err := s.DB.Exec(
+		"UPDATE sessions SET last_seen_at = ?, expires_at = ? WHERE id = ?",
+		session.LastSeenAt,
+		session.ExpiresAt,
+		session.ID,
+	)
+	return renewed, err
+}
+
+// GetByUserID récupère les sessions actives d'un utilisateur, les plus récentes d'abord
+func (s *SessionStore) GetByUserID(userID int64) ([]*Session, error) {
+	query := `SELECT id, user_id, token_hash, ip_address, user_agent, created_at, last_seen_at, expires_at
+              FROM sessions WHERE user_id = ? AND expires_at > ?
+              ORDER BY last_seen_at DESC`
+
+	rows, err := s.DB.Query(query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		var session Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.TokenHash,
+			&session.IPAddress,
+			&session.UserAgent,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteByID révoque une session précise appartenant à un utilisateur
+func (s *SessionStore) DeleteByID(id, userID int64) error {
+	res, err := s.DB.Exec("DELETE FROM sessions WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteByToken révoque la session associée à un jeton
+func (s *SessionStore) DeleteByToken(token string) error {
+	_, err := s.DB.Exec("DELETE FROM sessions WHERE token_hash = ?", hashSessionToken(token))
+	return err
+}
+
+// DeleteByUserID révoque toutes les sessions d'un utilisateur
+func (s *SessionStore) DeleteByUserID(userID int64) error {
+	_, err := s.DB.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+	return err
+}
+
+// GetFormattedLastSeen retourne la date de dernière activité formatée
+func (s *Session) GetFormattedLastSeen() string {
+	return s.LastSeenAt.Format("02 Jan 2006 à 15:04")
+}
+
+// DeleteExpired supprime les sessions expirées
+func (s *SessionStore) DeleteExpired() (int64, error) {
+	res, err := s.DB.Exec("DELETE FROM sessions WHERE expires_at <= ?", time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"forum/database"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestDB crée une base temporaire avec toutes les migrations appliquées
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "forum.db")+"?_foreign_keys=on&_timeout=5000")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// newTestUser enregistre un utilisateur dans la base de test
+func newTestUser(t *testing.T, db *sql.DB, username string) *User {
+	t.Helper()
+	user := &User{
+		UUID:     "uuid-" + username,
+		Username: username,
+		Email:    fmt.Sprintf("%s@exemple.fr", username),
+		Password: "hash",
+	}
+	if err := NewUserStore(db).Create(user); err != nil {
+		t.Fatalf("failed to create user %s: %v", username, err)
+	}
+	return user
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	store := NewSessionStore(db)
+	user := newTestUser(t, db, "alice")
+
+	session, token, err := store.Create(user.ID, "127.0.0.1", "go-test")
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if token == "" || session.TokenHash == token {
+		t.Fatal("the token must be returned in clear and stored hashed")
+	}
+
+	found, err := store.GetByToken(token)
+	if err != nil {
+		t.Fatalf("GetByToken() failed: %v", err)
+	}
+	if found.ID != session.ID || found.UserID != user.ID {
+		t.Errorf("GetByToken() = session %d of user %d, want %d of %d", found.ID, found.UserID, session.ID, user.ID)
+	}
+	if _, err := store.GetByToken(session.TokenHash); err == nil {
+		t.Error("the stored hash must not be accepted as a token")
+	}
+
+	if err := store.DeleteByToken(token); err != nil {
+		t.Fatalf("DeleteByToken() failed: %v", err)
+	}
+	if _, err := store.GetByToken(token); err != sql.ErrNoRows {
+		t.Errorf("GetByToken() after logout: err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	db := newTestDB(t)
+	store := NewSessionStore(db)
+	user := newTestUser(t, db, "alice")
+
+	session, token, err := store.Create(user.ID, "", "")
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if _, err := db.Exec("UPDATE sessions SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute), session.ID); err != nil {
+		t.Fatalf("failed to expire session: %v", err)
+	}
+
+	if _, err := store.GetByToken(token); err != sql.ErrNoRows {
+		t.Errorf("GetByToken() on an expired session: err = %v, want sql.ErrNoRows", err)
+	}
+	if n, err := store.DeleteExpired(); err != nil || n != 1 {
+		t.Errorf("DeleteExpired() = %d, %v, want 1, nil", n, err)
+	}
+}
+
+func TestSessionTouch(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		lastSeen    time.Time
+		expiresAt   time.Time
+		wantRenewed bool
+		wantWrite   bool
+	}{
+		{"activité récente", now.Add(-time.Second), now.Add(SessionDuration), false, false},
+		{"activité ancienne", now.Add(-2 * sessionTouchInterval), now.Add(SessionDuration), false, true},
+		{"session à renouveler", now.Add(-time.Second), now.Add(SessionRenewAfter - time.Minute), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			store := NewSessionStore(db)
+			user := newTestUser(t, db, "alice")
+
+			session, _, err := store.Create(user.ID, "", "")
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			session.LastSeenAt = tt.lastSeen
+			session.ExpiresAt = tt.expiresAt
+			before := session.LastSeenAt
+
+			renewed, err := store.Touch(session)
+			if err != nil {
+				t.Fatalf("Touch() failed: %v", err)
+			}
+			if renewed != tt.wantRenewed {
+				t.Errorf("Touch() renewed = %v, want %v", renewed, tt.wantRenewed)
+			}
+			if written := !session.LastSeenAt.Equal(before); written != tt.wantWrite {
+				t.Errorf("Touch() updated last_seen_at = %v, want %v", written, tt.wantWrite)
+			}
+			if tt.wantRenewed && session.ExpiresAt.Before(now.Add(SessionDuration-time.Minute)) {
+				t.Errorf("Touch() left the session expiring at %v", session.ExpiresAt)
+			}
+		})
+	}
+}
@@ -110,6 +110,32 @@ func (s *TagStore) GetAllTags() ([]*Tag, error) {
 	return tags, nil
 }
 
+// ListAfter récupère au plus limit tags dont le nom suit after, par ordre alphabétique
+func (s *TagStore) ListAfter(after string, limit int) ([]*Tag, error) {
+	query := `SELECT id, name, description, created_at FROM tags WHERE name > ? ORDER BY name LIMIT ?`
+
+	rows, err := s.DB.Query(query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Description, &tag.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
 // Update met à jour un tag existant
 func (s *TagStore) Update(tag *Tag) error {
 	query := `
@@ -134,6 +160,36 @@ func (s *TagStore) Delete(id int64) error {
 	return err
 }
 
+// Merge rattache les posts du tag source au tag cible puis supprime le tag source.
+// Retourne le nombre de posts nouvellement associés au tag cible.
+func (s *TagStore) Merge(sourceID, targetID int64) (int64, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT OR IGNORE INTO post_tags (post_id, tag_id) SELECT post_id, ? FROM post_tags WHERE tag_id = ?`,
+		targetID,
+		sourceID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// Les associations restantes du tag source sont supprimées en cascade
+	if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, sourceID); err != nil {
+		return 0, err
+	}
+
+	return moved, tx.Commit()
+}
+
 // GetTagsByPostID récupère tous les tags associés à un post
 func (s *TagStore) GetTagsByPostID(postID int64) ([]*Tag, error) {
 	query := `
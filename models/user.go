@@ -2,6 +2,8 @@ package models
 
 import (
 	"database/sql"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,15 +17,51 @@ const (
 	RoleAdmin
 )
 
+// Valeurs de la colonne TEXT users.role
+var roleNames = map[UserRole]string{
+	RoleGuest:     "guest",
+	RoleUser:      "user",
+	RoleModerator: "moderator",
+	RoleAdmin:     "admin",
+}
+
+// String retourne la valeur stockée en base pour un rôle
+func (r UserRole) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return roleNames[RoleUser]
+}
+
+// ParseUserRole convertit la colonne users.role en UserRole.
+// Les anciennes valeurs numériques ("1", "2"...) sont également acceptées.
+func ParseUserRole(value string) UserRole {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for role, name := range roleNames {
+		if name == value {
+			return role
+		}
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= int(RoleGuest) && n <= int(RoleAdmin) {
+		return UserRole(n)
+	}
+	return RoleUser
+}
+
 type User struct {
 	ID        int64
 	UUID      string
 	Username  string
 	Email     string
 	Password  string
+	Role      UserRole
 	AvatarURL string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	BannedAt  time.Time
+	BanReason string
+	// Les dislikes de l'utilisateur n'apparaissent pas dans les listes de réactions
+	AnonymousDislikes bool
 }
 
 type UserStore struct {
@@ -60,80 +98,88 @@ func (s *UserStore) Create(user *User) error {
 	return nil
 }
 
-func (s *UserStore) GetByID(id int64) (*User, error) {
-	query := `SELECT id, uuid, username, email, password, avatar_url, created_at, updated_at 
-              FROM users WHERE id = ?`
+const userColumns = `id, uuid, username, email, password, role, avatar_url, created_at, updated_at, banned_at, ban_reason, anonymous_dislikes`
 
+func scanUser(scanner interface{ Scan(...interface{}) error }) (*User, error) {
 	var user User
-	err := s.DB.QueryRow(query, id).Scan(
+	var role string
+	var bannedAt sql.NullTime
+	err := scanner.Scan(
 		&user.ID,
 		&user.UUID,
 		&user.Username,
 		&user.Email,
 		&user.Password,
+		&role,
 		&user.AvatarURL,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&bannedAt,
+		&user.BanReason,
+		&user.AnonymousDislikes,
 	)
 	if err != nil {
 		return nil, err
 	}
+	user.Role = ParseUserRole(role)
+	if bannedAt.Valid {
+		user.BannedAt = bannedAt.Time
+	}
 	return &user, nil
 }
 
+func (s *UserStore) GetByID(id int64) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+	return scanUser(s.DB.QueryRow(query, id))
+}
+
 func (s *UserStore) GetByEmail(email string) (*User, error) {
-	query := `SELECT id, uuid, username, email, password, avatar_url, created_at, updated_at 
-              FROM users WHERE email = ?`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+	return scanUser(s.DB.QueryRow(query, email))
+}
 
-	var user User
-	err := s.DB.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.UUID,
-		&user.Username,
-		&user.Email,
-		&user.Password,
-		&user.AvatarURL,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+func (s *UserStore) GetByUsername(username string) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = ?`
+	return scanUser(s.DB.QueryRow(query, username))
+}
 
+// List retourne au plus limit utilisateurs dont l'ID suit afterID, par ID croissant
+func (s *UserStore) List(afterID int64, limit int) ([]*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id > ? ORDER BY id ASC LIMIT ?`
+	rows, err := s.DB.Query(query, afterID, limit)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to query users: %w", err)
 	}
-	return &user, nil
-}
-
-func (s *UserStore) GetByUsername(username string) (*User, error) {
-	query := `SELECT id, uuid, username, email, password, role, created_at FROM users WHERE username = ?`
+	defer rows.Close()
 
-	var user User
-	err := s.DB.QueryRow(query, username).Scan(
-		&user.ID,
-		&user.UUID,
-		&user.Username,
-		&user.Email,
-		&user.Password,
-		&user.CreatedAt,
-	)
+	users := []*User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
 
-	if err != nil {
-		return nil, err
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return &user, nil
+	return users, nil
 }
 
 func (s *UserStore) UpdateRole(userID int64, role UserRole) error {
-	query := `UPDATE users SET role = ? WHERE id = ?`
+	query := `UPDATE users SET role = ?, updated_at = ? WHERE id = ?`
 
-	_, err := s.DB.Exec(query, role, userID)
+	_, err := s.DB.Exec(query, role.String(), time.Now(), userID)
 	return err
 }
 
+// GetAllModerators récupère les modérateurs et administrateurs
 func (s *UserStore) GetAllModerators() ([]*User, error) {
-	query := `SELECT id, uuid, username, email, role, created_at FROM users WHERE role = ?`
+	query := `SELECT id, uuid, username, email, role, created_at FROM users WHERE role IN (?, ?)`
 
-	rows, err := s.DB.Query(query, RoleModerator)
+	rows, err := s.DB.Query(query, RoleModerator.String(), RoleAdmin.String())
 	if err != nil {
 		return nil, err
 	}
@@ -142,16 +188,19 @@ func (s *UserStore) GetAllModerators() ([]*User, error) {
 	var users []*User
 	for rows.Next() {
 		var user User
+		var role string
 		err := rows.Scan(
 			&user.ID,
 			&user.UUID,
 			&user.Username,
 			&user.Email,
+			&role,
 			&user.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		user.Role = ParseUserRole(role)
 		users = append(users, &user)
 	}
 
@@ -162,6 +211,43 @@ func (s *UserStore) GetAllModerators() ([]*User, error) {
 	return users, nil
 }
 
+// Ban suspend un compte utilisateur
+func (s *UserStore) Ban(userID int64, reason string) error {
+	_, err := s.DB.Exec(
+		"UPDATE users SET banned_at = ?, ban_reason = ?, updated_at = ? WHERE id = ?",
+		time.Now(),
+		reason,
+		time.Now(),
+		userID,
+	)
+	return err
+}
+
+// Unban lève la suspension d'un compte utilisateur
+func (s *UserStore) Unban(userID int64) error {
+	_, err := s.DB.Exec(
+		"UPDATE users SET banned_at = NULL, ban_reason = '', updated_at = ? WHERE id = ?",
+		time.Now(),
+		userID,
+	)
+	return err
+}
+
+// IsBanned indique si le compte est suspendu
+func (u *User) IsBanned() bool {
+	return !u.BannedAt.IsZero()
+}
+
+// HasRole indique si l'utilisateur possède au moins le rôle demandé
+func (u *User) HasRole(role UserRole) bool {
+	return u.Role >= role
+}
+
+// IsModerator indique si l'utilisateur peut modérer le contenu des autres
+func (u *User) IsModerator() bool {
+	return u.HasRole(RoleModerator)
+}
+
 // GetFormattedJoinDate retourne la date d'inscription formatée
 func (u *User) GetFormattedJoinDate() string {
 	return u.CreatedAt.Format("January 2006")
@@ -204,6 +290,17 @@ func (s *UserStore) UpdateProfile(userID int64, username, email string) error {
 	return err
 }
 
+// SetAnonymousDislikes enregistre la préférence d'anonymat des dislikes
+func (s *UserStore) SetAnonymousDislikes(userID int64, anonymous bool) error {
+	_, err := s.DB.Exec(
+		"UPDATE users SET anonymous_dislikes = ?, updated_at = ? WHERE id = ?",
+		anonymous,
+		time.Now(),
+		userID,
+	)
+	return err
+}
+
 // GetCommentsByUserID récupère tous les commentaires d'un utilisateur
 func (s *CommentStore) GetCommentsByUserID(userID int64) ([]*Comment, error) {
 	query := `SELECT id, post_id, user_id, content, created_at, updated_at, like_count, dislike_count 
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"forum/models"
+)
+
+// runTrashPurge efface régulièrement les posts restés dans la corbeille
+// au-delà de models.TrashRetention
+func runTrashPurge(postStore *models.PostStore, interval time.Duration) {
+	for {
+		if n, err := purgeTrash(postStore); err != nil {
+			log.Printf("Échec de la purge de la corbeille: %v", err)
+		} else if n > 0 {
+			log.Printf("%d post(s) supprimé(s) définitivement", n)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// purgeTrash supprime définitivement les posts expirés et leurs images
+func purgeTrash(postStore *models.PostStore) (int, error) {
+	posts, err := postStore.GetExpiredDeleted(time.Now().Add(-models.TrashRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, post := range posts {
+		if err := postStore.Purge(post.ID); err != nil {
+			log.Printf("Échec de la suppression du post %d: %v", post.ID, err)
+			continue
+		}
+		if post.ImageURL != "" {
+			if err := os.Remove("." + post.ImageURL); err != nil && !os.IsNotExist(err) {
+				log.Printf("Échec de la suppression de l'image %s: %v", post.ImageURL, err)
+			}
+		}
+		purged++
+	}
+
+	return purged, nil
+}